@@ -11,16 +11,80 @@ import "GoTicTacToe/game"
 // AIModel defines the interface for AI player strategies.
 //
 // Implementations must provide a NextMove method that analyzes the current
-// board state and returns the coordinates of the chosen move.
+// game state and returns the chosen move.
 type AIModel interface {
 	// NextMove selects the best move for the given player.
 	//
 	// Parameters:
-	//   - board: the current game board state
+	//   - g: the current game (board, players, rules and options such as misère);
+	//     it must not be modified
 	//   - me: the player for whom to compute the move
-	//   - players: all players in the game (for opponent analysis)
 	//
-	// Returns the (x, y) coordinates of the chosen cell.
-	// Returns (-1, -1) if no valid move is available.
-	NextMove(board *game.Board, me *game.Player, players []*game.Player) (x, y int)
+	// Returns the chosen move: a placement, or a slide (game.Move.HasSource)
+	// during a movement phase. The boolean is false if no valid move is
+	// available.
+	NextMove(g *game.Game, me *game.Player) (game.Move, bool)
+}
+
+// TurnPlanner is implemented by AI models that can plan every placement of
+// a multi-placement turn at once (see game.TurnSchedule), e.g. the two
+// stones of a Connect6 turn.
+type TurnPlanner interface {
+	AIModel
+
+	// NextTurn selects the moves for the placements left in the current
+	// turn of the given player, in the order they must be played.
+	// It may return fewer moves, for example when one of them ends the round.
+	// Returns nil if no valid move is available.
+	NextTurn(g *game.Game, me *game.Player) []game.Move
+}
+
+// NextTurn asks model for the moves of the current turn of me.
+//
+// Models implementing TurnPlanner plan the whole turn; other models
+// return a single move and are asked again for the next placement.
+// Returns nil if no valid move is available.
+func NextTurn(model AIModel, g *game.Game, me *game.Player) []game.Move {
+	if planner, ok := model.(TurnPlanner); ok {
+		return planner.NextTurn(g, me)
+	}
+
+	m, ok := model.NextMove(g, me)
+	if !ok {
+		return nil
+	}
+	return []game.Move{m}
+}
+
+// Model names identify AI strategies outside of the program,
+// for example in saved game records.
+const (
+	RandomModelName  = "random"
+	MinimaxModelName = "minimax"
+)
+
+// ModelName returns the name of the given AI model,
+// or an empty string if the model is nil or unknown.
+func ModelName(model AIModel) string {
+	switch model.(type) {
+	case RandomAI:
+		return RandomModelName
+	case MinimaxAI:
+		return MinimaxModelName
+	default:
+		return ""
+	}
+}
+
+// ModelByName returns the AI model registered under the given name.
+// The boolean is false if no model has that name.
+func ModelByName(name string) (AIModel, bool) {
+	switch name {
+	case RandomModelName:
+		return RandomAI{}, true
+	case MinimaxModelName:
+		return MinimaxAI{}, true
+	default:
+		return nil, false
+	}
 }
@@ -1,117 +1,744 @@
 package ai_models
 
-import "GoTicTacToe/game"
+import (
+	"GoTicTacToe/game"
+	"errors"
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+// errUnsupportedRules is used internally when the rules in use cannot be
+// modeled by the search.
+var errUnsupportedRules = errors.New("minimax: unsupported rules")
 
 // MinimaxAI is an AI player using the Minimax algorithm.
 // It is designed for two-player, deterministic, perfect-information games
 // such as Tic-Tac-Toe.
 //
+// The search runs on a game.Bitboard with alpha-beta pruning, so no board is
+// cloned while exploring the game tree. Small positions are solved exactly;
+// larger ones are searched to a fixed depth and scored with a line heuristic.
+//
+// In misère, completing a line is scored as a loss and the heuristic is
+// reversed, so the AI steers away from lines instead of building them.
+// With line scoring (game.Game.LineScoring), completed lines do not end the
+// search: they are counted for their side until the board is full, where
+// the side with the most lines (the fewest in misère) wins.
+// With a mark limit (game.Game.MaxMarks), the search removes the oldest mark
+// of a player exactly like the game does. With a turn schedule (Connect6),
+// it plays every placement of a turn before handing over to the opponent,
+// and NextTurn returns the whole turn at once.
+//
+// Two teams are searched like two players: a player scores the positions
+// for their whole team, so teammates are treated as allies whatever the turn
+// order. Each player keeps their own marks, which form lines with the ones
+// of their teammates when they are shared (game.Board.TeamLines).
+//
 // In the classic 3x3 Tic-Tac-Toe, this strategy is unbeatable (optimal play).
 type MinimaxAI struct{}
 
 // Minimax evaluation scores.
 //
-// These constants are intentionally kept small and symmetric:
-// - win  => +1
-// - loss => -1
-// - draw =>  0
+// Scores are symmetric: a win is worth scoreWin minus the number of plies
+// needed to reach it (faster wins are preferred, slower losses too), a draw is
+// worth scoreDraw and heuristic scores always stay far below scoreWin.
 //
 // Sentinel values are used as initial "worst possible" bounds when searching.
 const (
-	scoreWin  = 1
+	scoreWin  = 1_000_000
 	scoreDraw = 0
-	scoreLoss = -1
+	scoreLoss = -scoreWin
 
 	// initialLowerBound is used to initialize the best score in maximizing turns.
 	// It must be strictly lower than the minimal possible score (scoreLoss).
-	initialLowerBound = -9999
+	initialLowerBound = scoreLoss - 1
 
 	// initialUpperBound is used to initialize the best score in minimizing turns.
 	// It must be strictly higher than the maximal possible score (scoreWin).
-	initialUpperBound = 9999
+	initialUpperBound = scoreWin + 1
+)
+
+// Search limits and heuristic tuning.
+const (
+	// fullSearchEmptyCells is the number of empty cells up to which the game
+	// tree is searched to the end (3x3 boards always are).
+	fullSearchEmptyCells = 10
+
+	// defaultSearchDepth is the minimal depth (in plies) used on larger positions.
+	defaultSearchDepth = 4
+
+	// wideSearchDepth is the minimal depth used when every empty cell is a
+	// candidate (misère, custom line sets), as the branching factor is much higher.
+	wideSearchDepth = 2
+
+	// searchNodeBudget bounds the estimated tree size (branching^depth) when
+	// deepening a depth-limited search, to keep the AI responsive.
+	searchNodeBudget = 200_000
+
+	// lineWeightBase is the factor applied per mark in an open line by the heuristic.
+	lineWeightBase = 8
 )
 
-// NextMove returns the best move (x, y) for the current player according to Minimax.
+// NextMove returns the best move for the current player according to Minimax.
 //
-// The current implementation supports two-player games only.
-// If the number of players is not exactly two, it falls back to RandomAI
-// to avoid undefined behavior (e.g., "opponent" not well-defined).
-func (MinimaxAI) NextMove(board *game.Board, me *game.Player, players []*game.Player) (int, int) {
-	if len(players) != 2 {
-		return RandomAI{}.NextMove(board, me, players)
+// The search understands the classic rules, sliding rules (game.SlidingRules)
+// and rulesets defined by a set of winning lines (game.LineRules, e.g. 3D
+// boards). The current implementation supports two sides: two players, or two
+// teams (see MinimaxAI). If there are more sides, the rules are not supported,
+// or the board does not fit in a bitboard, it falls back to RandomAI to avoid
+// undefined behavior (e.g., "opponent" not well-defined).
+func (MinimaxAI) NextMove(g *game.Game, me *game.Player) (game.Move, bool) {
+	s, meIdx, ok := newGameSearch(g, me)
+	if !ok {
+		return RandomAI{}.NextMove(g, me)
 	}
 
-	bestScore := initialLowerBound
-	bestMove := game.Move{X: -1, Y: -1}
+	mv, ok := s.bestMove(meIdx)
+	if !ok {
+		return game.Move{}, false
+	}
+	return s.gameMove(mv), true
+}
+
+// NextTurn returns the best moves for every placement left in the current
+// turn, stopping early if one of them wins (or, in misère, loses) the round
+// without line scoring. Like NextMove, it falls back to RandomAI when the game cannot be searched.
+func (MinimaxAI) NextTurn(g *game.Game, me *game.Player) []game.Move {
+	s, meIdx, ok := newGameSearch(g, me)
+	if !ok {
+		return NextTurn(RandomAI{}, g, me)
+	}
+
+	var moves []game.Move
+	for left := g.PlacementsLeft(); left > 0; left-- {
+		mv, ok := s.bestMove(meIdx)
+		if !ok {
+			break
+		}
+		moves = append(moves, s.gameMove(mv))
+
+		s.apply(meIdx, mv)
+		if !s.lineScoring && s.wins(meIdx, mv.to) {
+			break
+		}
+		s.turn = s.turn.next(s.schedule)
+	}
+	return moves
+}
+
+// newGameSearch prepares a search of g for player me.
+// It returns the search, the index of me in the bitboard, and false if the
+// game cannot be modeled by the search.
+func newGameSearch(g *game.Game, me *game.Player) (*search, int, bool) {
+	players := g.Players
+	sides := searchSides(players)
+	if slices.Max(sides) != 1 {
+		return nil, 0, false
+	}
+	meIdx := slices.Index(players, me)
+
+	var bb *game.Bitboard
+	var err error
+	customLines := false
+	slideAfter := 0
+	switch rules := g.Rules.(type) {
+	case nil, game.StandardRules:
+		bb, err = game.BitboardFromBoard(g.Board, players)
+	case game.SlidingRules:
+		bb, err = game.BitboardFromBoard(g.Board, players)
+		slideAfter = rules.Placements
+	case game.LineRules:
+		bb, err = game.BitboardFromBoardWithLines(g.Board, players, rules.WinningLines(g))
+		customLines = true
+	default:
+		err = errUnsupportedRules
+	}
+	if meIdx < 0 || err != nil {
+		return nil, 0, false
+	}
+
+	opts := searchOptions{
+		misere:      g.Misere,
+		lineScoring: g.LineScoring,
+		lines:       make([]int, 2),
+		customLines: customLines,
+		maxMarks:    g.MaxMarks,
+		schedule:    g.Schedule,
+		turn:        turnState{number: g.Turn(), left: g.PlacementsLeft()},
+		slideAfter:  slideAfter,
+		placed:      make([]int, len(players)),
+		player:      meIdx,
+		sides:       sides,
+		teamLines:   g.Board.TeamLines,
+	}
+	for i, p := range players {
+		opts.lines[sides[i]] += p.Lines
+	}
+	for _, rec := range g.History() {
+		for i, p := range players {
+			if rec.Player == p && !rec.HasSource {
+				opts.placed[i]++
+			}
+		}
+	}
+	if g.MaxMarks > 0 {
+		for _, p := range players {
+			var cells []int
+			for _, m := range g.MarksOf(p) {
+				cells = append(cells, bb.Index(m.X, m.Y))
+			}
+			opts.marks = append(opts.marks, cells)
+		}
+	}
+	return newSearch(bb, opts), meIdx, true
+}
+
+// searchSides returns the side of each player: the index of their team, or
+// of themselves without one, among the sides in turn order.
+func searchSides(players []*game.Player) []int {
+	var reps []*game.Player
+	sides := make([]int, len(players))
+	for i, p := range players {
+		side := slices.IndexFunc(reps, p.SameSide)
+		if side < 0 {
+			side = len(reps)
+			reps = append(reps, p)
+		}
+		sides[i] = side
+	}
+	return sides
+}
+
+// search holds the state of one minimax search on a bitboard.
+type search struct {
+	bb     *game.Bitboard
+	depth  int   // Maximum depth in plies
+	exact  bool  // True if the whole game tree is explored
+	misere bool  // Completing a line loses
+	wide   bool  // Every empty cell is a candidate, not only neighbors
+	order  []int // Cells sorted from the center outwards, for better pruning
+
+	// With a mark limit, placing a mark beyond maxMarks removes the oldest one.
+	// marks[p][first[p]:] are the cells of player p, oldest first.
+	maxMarks int
+	marks    [][]int
+	first    []int
+
+	// With a turn schedule, a player may place several marks in a row.
+	schedule game.TurnSchedule
+	turn     turnState // Turn of the player to move
 
-	for _, mv := range board.AvailableMoves() {
-		clone := board.Clone()
-		clone.Play(me, mv.X, mv.Y)
+	// With sliding rules, players slide their marks once they have placed
+	// slideAfter of them (0 = never).
+	slideAfter int
+	placed     []int // Marks placed by each player
 
-		score := minimax(clone, me, players, false)
+	// Players take turns in the order of the bitboard. Each belongs to one
+	// of two sides, whose marks form lines together with teamLines.
+	sides     []int
+	teamLines bool
 
+	// With line scoring, lines[side] counts the lines completed by side and
+	// the game only ends once the board is full.
+	lineScoring bool
+	lines       []int
+}
+
+// searchMove is a move on the bitboard: a placement on cell to,
+// or a slide from cell from to cell to.
+type searchMove struct {
+	from int // Source cell of a slide, noSource for a placement
+	to   int // Target cell
+}
+
+// noSource is the source cell of a placement.
+const noSource = -1
+
+// turnState locates a placement within the turn schedule.
+type turnState struct {
+	number int // Turn number (1-based)
+	left   int // Placements left in the turn, including the current one
+}
+
+// next returns the state after one more placement.
+func (t turnState) next(schedule game.TurnSchedule) turnState {
+	if t.left > 1 {
+		return turnState{number: t.number, left: t.left - 1}
+	}
+	return turnState{number: t.number + 1, left: schedule.Placements(t.number + 1)}
+}
+
+// searchOptions describes the game options the search must follow.
+type searchOptions struct {
+	misere      bool    // Completing a line loses
+	customLines bool    // The bitboard uses custom winning lines
+	maxMarks    int     // Maximum number of marks per player (0 = unlimited)
+	marks       [][]int // Cells of each player, oldest first (with maxMarks)
+
+	schedule game.TurnSchedule // Number of marks placed in each turn
+	turn     turnState         // Turn of the player to move at the root
+
+	slideAfter int   // Marks placed by each player before sliding (0 = never)
+	placed     []int // Marks placed so far by each player
+	player     int   // Player to move at the root
+
+	sides     []int // Side (0 or 1) of each player
+	teamLines bool  // Marks of a side form lines together
+
+	lineScoring bool  // Lines score points instead of ending the game
+	lines       []int // Lines completed so far by each side
+}
+
+// newSearch prepares a search, orders the cells and picks its depth.
+//
+// Positions with few empty cells are searched to the end. Otherwise the
+// depth starts at defaultSearchDepth and grows while the estimated tree
+// size stays within searchNodeBudget, so narrow games (e.g. with gravity)
+// are searched deeper than wide ones. Wide searches (misère, or custom
+// lines where cell adjacency is meaningless) start at wideSearchDepth.
+// With a mark limit or sliding marks the game may never end, so it is
+// never searched to the end. With multi-placement turns, the depth is reduced
+// (down to wideSearchDepth) until the estimated tree size fits the budget.
+func newSearch(bb *game.Bitboard, opts searchOptions) *search {
+	s := &search{
+		bb:       bb,
+		depth:    defaultSearchDepth,
+		misere:   opts.misere,
+		wide:     opts.misere || opts.customLines,
+		maxMarks: opts.maxMarks,
+		marks:    opts.marks,
+		first:    make([]int, len(opts.marks)),
+		schedule: opts.schedule,
+		turn:     opts.turn,
+
+		slideAfter: opts.slideAfter,
+		placed:     opts.placed,
+
+		sides:     opts.sides,
+		teamLines: opts.teamLines,
+
+		lineScoring: opts.lineScoring,
+		lines:       opts.lines,
+	}
+	if s.wide {
+		s.depth = wideSearchDepth
+	}
+
+	cells := bb.Width * bb.Height
+	s.order = make([]int, cells)
+	for i := range s.order {
+		s.order[i] = i
+	}
+	if opts.customLines {
+		// Without a 2D layout, cells lying on more lines are the central ones
+		sort.SliceStable(s.order, func(a, b int) bool {
+			return bb.LineCount(s.order[a]) > bb.LineCount(s.order[b])
+		})
+	} else {
+		sort.SliceStable(s.order, func(a, b int) bool {
+			return s.centerDistance(s.order[a]) < s.centerDistance(s.order[b])
+		})
+	}
+
+	// Marks vanishing or sliding let games go on forever
+	endless := s.maxMarks > 0 || s.slideAfter > 0
+
+	empty := bits.OnesCount64(bb.Empty())
+	if empty <= fullSearchEmptyCells && !endless {
+		s.depth = empty
+		s.exact = true
+		return s
+	}
+
+	branching := len(s.moves(opts.player))
+	if !opts.schedule.IsSingle() {
+		// Consecutive placements of the same player prune poorly:
+		// shrink the depth to fit the budget instead of deepening.
+		for s.depth > wideSearchDepth && treeSize(branching, s.depth) > searchNodeBudget {
+			s.depth--
+		}
+		return s
+	}
+	if branching > 1 {
+		for (endless || s.depth < empty) && treeSize(branching, s.depth+1) <= searchNodeBudget {
+			s.depth++
+		}
+	}
+	return s
+}
+
+// treeSize returns branching^depth, saturating above searchNodeBudget.
+func treeSize(branching, depth int) int {
+	size := 1
+	for i := 0; i < depth && size <= searchNodeBudget; i++ {
+		size *= branching
+	}
+	return size
+}
+
+// bestMove returns the best move for player.
+// The boolean is false if player has no move.
+func (s *search) bestMove(player int) (searchMove, bool) {
+	bestScore := initialLowerBound
+	var best searchMove
+	found := false
+
+	for _, mv := range s.moves(player) {
+		score := s.scoreMove(player, mv, initialLowerBound, initialUpperBound, 0)
 		if score > bestScore {
 			bestScore = score
-			bestMove = mv
+			best = mv
+			found = true
 		}
 	}
+	return best, found
+}
 
-	return bestMove.X, bestMove.Y
+// gameMove converts a bitboard move to a game move.
+func (s *search) gameMove(mv searchMove) game.Move {
+	x, y := s.bb.Coords(mv.to)
+	if mv.from == noSource {
+		return game.NewMove(x, y)
+	}
+	fx, fy := s.bb.Coords(mv.from)
+	return game.NewSlide(fx, fy, x, y)
 }
 
-// minimax recursively evaluates the game tree from the perspective of "me".
+// scoreMove plays mv for player, scores the resulting position from
+// player's point of view and undoes the move.
 //
-// Parameters:
-// - board: current board state
-// - me: the player for which we are computing the best outcome
-// - players: list of players (expected length: 2)
-// - maximizing: true if it's "me" turn, false if it's opponent's turn
+// If player has placements left in their turn, they move again. If the next
+// player is a teammate, the score of the position is also theirs instead of
+// the opponent's.
+func (s *search) scoreMove(player int, mv searchMove, alpha, beta, ply int) int {
+	removed := s.apply(player, mv)
+	defer s.undo(player, mv, removed)
+
+	if s.lineScoring {
+		side := s.sides[player]
+		n := s.bb.LinesAt(s.lineBits(player), mv.to)
+		s.lines[side] += n
+		defer func() { s.lines[side] -= n }()
+	} else if s.wins(player, mv.to) {
+		if s.misere {
+			return scoreLoss + ply
+		}
+		return scoreWin - ply
+	}
+
+	turn := s.turn
+	s.turn = turn.next(s.schedule)
+	defer func() { s.turn = turn }()
+
+	if turn.left > 1 {
+		return s.negamax(player, alpha, beta, ply+1)
+	}
+	next := (player + 1) % len(s.sides)
+	if s.sides[next] == s.sides[player] {
+		return s.negamax(next, alpha, beta, ply+1)
+	}
+	return -s.negamax(next, -beta, -alpha, ply+1)
+}
+
+// wins reports whether the marks of player, with the ones of their
+// teammates when lines are shared, form a complete line through cell.
+func (s *search) wins(player, cell int) bool {
+	return s.bb.CompletesLineAt(s.lineBits(player), cell)
+}
+
+// lineBits returns the marks forming lines with the ones of player.
+func (s *search) lineBits(player int) uint64 {
+	if !s.teamLines {
+		return s.bb.Bits(player)
+	}
+	return s.sideBits(s.sides[player])
+}
+
+// sideBits returns the marks of every player of side.
+func (s *search) sideBits(side int) uint64 {
+	var set uint64
+	for p, ps := range s.sides {
+		if ps == side {
+			set |= s.bb.Bits(p)
+		}
+	}
+	return set
+}
+
+// apply plays mv for player. Returns the cell of the mark removed by the
+// mark limit, or -1.
+func (s *search) apply(player int, mv searchMove) int {
+	if mv.from == noSource {
+		s.placed[player]++
+		return s.place(player, mv.to)
+	}
+
+	s.bb.Unplay(player, mv.from)
+	s.bb.Play(player, mv.to)
+	s.moveMark(player, mv.from, mv.to)
+	return -1
+}
+
+// undo undoes apply.
+func (s *search) undo(player int, mv searchMove, removed int) {
+	if mv.from == noSource {
+		s.placed[player]--
+		s.unplace(player, mv.to, removed)
+		return
+	}
+
+	s.bb.Unplay(player, mv.to)
+	s.bb.Play(player, mv.from)
+	s.moveMark(player, mv.to, mv.from)
+}
+
+// moveMark updates the mark queue of player when a mark slides, the mark
+// keeping its age.
+func (s *search) moveMark(player, from, to int) {
+	if s.maxMarks == 0 {
+		return
+	}
+	queue := s.marks[player][s.first[player]:]
+	if i := slices.Index(queue, from); i >= 0 {
+		queue[i] = to
+	}
+}
+
+// place plays cell for player, removing their oldest mark if the mark
+// limit is exceeded. Returns the removed cell, or -1.
+func (s *search) place(player, cell int) int {
+	s.bb.Play(player, cell)
+	if s.maxMarks == 0 {
+		return -1
+	}
+
+	s.marks[player] = append(s.marks[player], cell)
+	if len(s.marks[player])-s.first[player] <= s.maxMarks {
+		return -1
+	}
+	removed := s.marks[player][s.first[player]]
+	s.first[player]++
+	s.bb.Unplay(player, removed)
+	return removed
+}
+
+// unplace undoes place.
+func (s *search) unplace(player, cell, removed int) {
+	s.bb.Unplay(player, cell)
+	if s.maxMarks == 0 {
+		return
+	}
+
+	s.marks[player] = s.marks[player][:len(s.marks[player])-1]
+	if removed >= 0 {
+		s.first[player]--
+		s.bb.Play(player, removed)
+	}
+}
+
+// negamax recursively evaluates the game tree from the point of view of
+// the player to move, using alpha-beta pruning.
 //
-// Returns an integer score among {scoreWin, scoreDraw, scoreLoss}.
+// Parameters:
+// - player: index of the player to move
+// - alpha, beta: current search window
+// - ply: distance from the root, used to prefer faster wins and stop at s.depth
 //
-// This version does not implement alpha-beta pruning (which would speed up search),
-// because Tic-Tac-Toe's state space is small. The code remains simple and readable.
-func minimax(board *game.Board, me *game.Player, players []*game.Player, maximizing bool) int {
-	// Terminal states: win/loss/draw
-	winner := board.CheckWin()
-	if winner == me {
-		return scoreWin
-	}
-	if winner != nil && winner != me {
-		return scoreLoss
-	}
-	if board.CheckDraw() {
+// Returns the score of the position for player.
+func (s *search) negamax(player, alpha, beta, ply int) int {
+	moves := s.moves(player)
+	if len(moves) == 0 {
+		return s.finalScore(player, ply)
+	}
+	if ply >= s.depth {
+		return s.evaluate(player)
+	}
+
+	best := initialLowerBound
+	for _, mv := range moves {
+		score := s.scoreMove(player, mv, alpha, beta, ply)
+		if score > best {
+			best = score
+		}
+		if best > alpha {
+			alpha = best
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// finalScore scores a position where player cannot move, which ends the
+// game: a draw, or with line scoring the result of the line count.
+func (s *search) finalScore(player, ply int) int {
+	if !s.lineScoring {
+		return scoreDraw
+	}
+
+	side := s.sides[player]
+	diff := s.lines[side] - s.lines[1-side]
+	if s.misere {
+		diff = -diff
+	}
+	switch {
+	case diff > 0:
+		return scoreWin - ply
+	case diff < 0:
+		return scoreLoss + ply
+	default:
 		return scoreDraw
 	}
+}
+
+// moves lists the moves of player worth exploring: slides of their marks
+// once they have placed all of them with sliding rules, placements on the
+// candidate cells otherwise.
+func (s *search) moves(player int) []searchMove {
+	if s.slideAfter > 0 && s.placed[player] >= s.slideAfter {
+		return s.slides(player)
+	}
+
+	cells := s.candidates()
+	moves := make([]searchMove, len(cells))
+	for i, cell := range cells {
+		moves[i] = searchMove{from: noSource, to: cell}
+	}
+	return moves
+}
+
+// slides lists every slide of a mark of player to an adjacent empty cell
+// (including diagonally), targets closest to the center first.
+func (s *search) slides(player int) []searchMove {
+	own := s.bb.Bits(player)
+	empty := s.bb.Empty()
+
+	var moves []searchMove
+	for _, to := range s.order {
+		if empty&(1<<to) == 0 {
+			continue
+		}
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				from, ok := s.bb.Neighbor(to, dx, dy)
+				if (dx == 0 && dy == 0) || !ok {
+					continue
+				}
+				if own&(1<<from) != 0 {
+					moves = append(moves, searchMove{from: from, to: to})
+				}
+			}
+		}
+	}
+	return moves
+}
 
-	// Maximizing: it's "me" turn.
-	if maximizing {
-		best := initialLowerBound
-		for _, mv := range board.AvailableMoves() {
-			clone := board.Clone()
-			clone.Play(me, mv.X, mv.Y)
+// candidates lists the cells worth exploring, center first.
+//
+// Exact searches consider every legal cell. Depth-limited searches only
+// consider empty cells next to an existing mark, as distant moves rarely
+// matter in k-in-a-row games and would blow up the branching factor.
+// With gravity every legal cell is kept, there is at most one per column.
+// Wide searches keep all cells: in misère distant moves are often the
+// safest ones, and with custom lines the board layout says nothing about
+// which cells are related.
+func (s *search) candidates() []int {
+	legal := s.bb.Moves()
+	occupied := s.bb.Occupied()
+	nearOnly := !s.exact && !s.bb.Gravity && !s.wide && occupied != 0
 
-			score := minimax(clone, me, players, false)
-			if score > best {
-				best = score
+	moves := make([]int, 0, bits.OnesCount64(legal))
+	for _, cell := range s.order {
+		if legal&(1<<cell) == 0 {
+			continue
+		}
+		if nearOnly && !s.hasNeighbor(cell, occupied) {
+			continue
+		}
+		moves = append(moves, cell)
+	}
+	return moves
+}
+
+// hasNeighbor reports whether one of the 8 cells around cell is occupied,
+// across the edges on a wrapping board.
+func (s *search) hasNeighbor(cell int, occupied uint64) bool {
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			nb, ok := s.bb.Neighbor(cell, dx, dy)
+			if (dx == 0 && dy == 0) || !ok {
+				continue
+			}
+			if occupied&(1<<nb) != 0 {
+				return true
 			}
 		}
-		return best
 	}
+	return false
+}
 
-	// Minimizing: opponent turn.
-	opp := me.Opponent(players)
-	best := initialUpperBound
+// evaluate scores a non-terminal position for the side of player.
+//
+// Every line still winnable by a single owner (a player, or a side when
+// lines are shared) counts for the side of that owner, with a weight growing
+// exponentially with the number of marks already in it. In misère such
+// lines are threats to oneself and the score is negated.
+func (s *search) evaluate(player int) int {
+	// Marks and side of each line owner
+	var sets []uint64
+	var sides []int
+	if s.teamLines {
+		sets = []uint64{s.sideBits(0), s.sideBits(1)}
+		sides = []int{0, 1}
+	} else {
+		for p := range s.sides {
+			sets = append(sets, s.bb.Bits(p))
+		}
+		sides = s.sides
+	}
 
-	for _, mv := range board.AvailableMoves() {
-		clone := board.Clone()
-		clone.Play(opp, mv.X, mv.Y)
+	score := 0
+	for _, m := range s.bb.Lines() {
+		owner, marks := -1, 0
+		for i, set := range sets {
+			if n := bits.OnesCount64(set & m); n > 0 {
+				if owner >= 0 {
+					owner = -1
+					break
+				}
+				owner, marks = i, n
+			}
+		}
 
-		score := minimax(clone, me, players, true)
-		if score < best {
-			best = score
+		switch {
+		case owner < 0:
+		case sides[owner] == s.sides[player]:
+			score += lineWeight(marks)
+		default:
+			score -= lineWeight(marks)
 		}
 	}
+	if s.misere {
+		return -score
+	}
+	return score
+}
 
-	return best
+// lineWeight returns the heuristic value of an open line holding n marks.
+func lineWeight(n int) int {
+	w := 1
+	for i := 0; i < n; i++ {
+		w *= lineWeightBase
+	}
+	return w
+}
+
+// centerDistance returns the squared distance (doubled coordinates) of cell to the center.
+func (s *search) centerDistance(cell int) int {
+	x, y := s.bb.Coords(cell)
+	dx := 2*x - (s.bb.Width - 1)
+	dy := 2*y - (s.bb.Height - 1)
+	return dx*dx + dy*dy
 }
@@ -3,26 +3,86 @@ package ai_models
 import (
 	"GoTicTacToe/game"
 	"math/rand"
+	"slices"
 )
 
-// invalidMoveCoord is returned when no valid move is available.
-const invalidMoveCoord = -1
-
 // RandomAI implements the AIModel interface with a random move selection strategy.
 //
-// This AI simply picks a random empty cell from the available moves,
-// providing an "easy" difficulty level suitable for casual play or testing.
+// This AI simply picks a random legal move, providing an "easy" difficulty
+// level suitable for casual play or testing. In misère, it still avoids the
+// moves completing a line of its own (and thus losing) whenever it has
+// another choice, with the rules where lines belong to the player whose
+// marks form them (see safeMoves).
 type RandomAI struct{}
 
-// NextMove selects a random available move on the board.
+// NextMove selects a random legal move, slides included.
 //
-// Returns (-1, -1) if the board has no empty cells.
-func (RandomAI) NextMove(board *game.Board, me *game.Player, players []*game.Player) (int, int) {
-	moves := board.AvailableMoves()
+// The boolean is false if no move is available.
+func (RandomAI) NextMove(g *game.Game, me *game.Player) (game.Move, bool) {
+	moves := g.LegalMoves()
 	if len(moves) == 0 {
-		return invalidMoveCoord, invalidMoveCoord
+		return game.Move{}, false
+	}
+
+	if g.Misere {
+		if safe := safeMoves(g, me, moves); len(safe) > 0 {
+			moves = safe
+		}
+	}
+
+	return moves[rand.Intn(len(moves))], true
+}
+
+// safeMoves returns the moves that do not complete a line of player. The
+// game is only read, never played on.
+//
+// It returns nil with the rules where lines are not owned, as completing
+// one is not losing then: the lines of Notakto and Order and Chaos do not
+// depend on who placed their marks, and spooky moves of quantum tic-tac-toe
+// place no mark at all.
+func safeMoves(g *game.Game, player *game.Player, moves []game.Move) []game.Move {
+	var completes func(m game.Move) bool
+	switch rules := g.Rules.(type) {
+	case nil, game.StandardRules, game.GomokuRules, game.PenteRules, game.SlidingRules, game.UnboundedRules:
+		store := g.Store()
+		completes = func(m game.Move) bool {
+			return store.CompletesLine(m.MarkOf(player), m)
+		}
+	case game.LineRules:
+		lines := rules.WinningLines(g)
+		completes = func(m game.Move) bool {
+			return completesWinningLine(g.Board, lines, player, m)
+		}
+	default:
+		return nil
+	}
+
+	var safe []game.Move
+	for _, m := range moves {
+		if !completes(m) {
+			safe = append(safe, m)
+		}
 	}
+	return safe
+}
 
-	selected := moves[rand.Intn(len(moves))]
-	return selected.X, selected.Y
+// completesWinningLine reports whether placing a mark of player on m
+// completes one of lines, player owning every other cell of it.
+func completesWinningLine(board *game.Board, lines [][]game.Move, player *game.Player, m game.Move) bool {
+	for _, line := range lines {
+		if !slices.Contains(line, m.Cell()) {
+			continue
+		}
+		owned := true
+		for _, c := range line {
+			if c != m.Cell() && board.At(c.X, c.Y) != player {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,101 @@
+package ai_models
+
+import (
+	"GoTicTacToe/game"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// newTestGame returns a misère game of two players on a width x height
+// board.
+func newTestGame(width, height, toWin int, rules game.Ruleset) *game.Game {
+	players := []*game.Player{game.NewPlayer(nil, color.Black), game.NewPlayer(nil, color.White)}
+	g := game.NewGameWithConfig(width, height, toWin, players)
+	g.Rules = rules
+	g.Misere = true
+	return g
+}
+
+func TestSafeMovesOnlyWithOwnedLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		game   func() *game.Game
+		moves  []game.Move // Played before the first player looks for safe moves
+		unsafe []game.Move // Moves left out
+		all    bool        // Every legal move is safe, the filter being off
+	}{
+		{
+			name:   "standard",
+			game:   func() *game.Game { return newTestGame(3, 3, 3, nil) },
+			moves:  []game.Move{game.NewMove(0, 0), game.NewMove(0, 2), game.NewMove(1, 0), game.NewMove(1, 2)},
+			unsafe: []game.Move{game.NewMove(2, 0)},
+		},
+		{
+			name:   "unbounded",
+			game:   func() *game.Game { return newTestGame(3, 3, 3, game.UnboundedRules{}) },
+			moves:  []game.Move{game.NewMove(0, 0), game.NewMove(0, 5), game.NewMove(1, 1), game.NewMove(1, 5)},
+			unsafe: []game.Move{game.NewMove(-1, -1), game.NewMove(2, 2)},
+		},
+		{
+			// Lines run across the layers, laid side by side on the flat
+			// board: (2, 0) next to (3, 0) and (4, 0) is no line.
+			name:   "3D",
+			game:   func() *game.Game { return newTestGame(9, 3, 3, game.Rules3D{Depth: 3}) },
+			moves:  []game.Move{game.NewMove(0, 0), game.NewMove(8, 2), game.NewMove(3, 0), game.NewMove(1, 2), game.NewMove(4, 0), game.NewMove(6, 1)},
+			unsafe: []game.Move{game.NewMove(5, 0), game.NewMove(6, 0), game.NewMove(8, 0)},
+		},
+		{
+			// Completing a line of any mark kills the board, but the
+			// filter does not know about dead boards.
+			name:  "notakto",
+			game:  func() *game.Game { return newTestGame(6, 3, 3, game.NotaktoRules{}) },
+			moves: []game.Move{game.NewMove(0, 0), game.NewMove(1, 0)},
+			all:   true,
+		},
+		{
+			name: "quantum",
+			game: func() *game.Game { return newTestGame(3, 3, 3, game.QuantumRules{}) },
+			all:  true,
+		},
+		{
+			name:  "order and chaos",
+			game:  func() *game.Game { return newTestGame(3, 3, 3, game.OrderChaosRules{}) },
+			moves: []game.Move{game.NewMove(0, 0), game.NewMove(1, 0)},
+			all:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.game()
+			for _, m := range tt.moves {
+				if err := g.Play(m); err != nil {
+					t.Fatalf("playing %v: %v", m, err)
+				}
+			}
+			if !g.IsPlaying() {
+				t.Fatalf("round over after %v", tt.moves)
+			}
+			me := g.Current
+			legal := g.LegalMoves()
+			history := len(g.History())
+
+			safe := safeMoves(g, me, legal)
+			if tt.all {
+				if safe != nil {
+					t.Fatalf("safeMoves() = %v, want no filter", safe)
+				}
+				return
+			}
+			for _, m := range legal {
+				if got, want := slices.Contains(safe, m), !slices.Contains(tt.unsafe, m); got != want {
+					t.Errorf("move %v kept: %v, want %v", m, got, want)
+				}
+			}
+			if len(g.History()) != history || g.Current != me {
+				t.Errorf("safeMoves() changed the game")
+			}
+		})
+	}
+}
@@ -31,10 +31,15 @@ import (
 
 // Font size constants used across the user interface.
 const (
+	smallFontSize  = 13
 	normalFontSize = 20
 	bigFontSize    = 80
 )
 
+// SmallFont is used for small annotations, such as the subscripts of
+// the spooky marks of quantum tic-tac-toe.
+var SmallFont text.Face
+
 // NormalFont is the default font used for standard UI text
 // such as labels, scores, and informational messages.
 var NormalFont text.Face
@@ -53,6 +58,11 @@ func init() {
 		log.Fatal(err)
 	}
 
+	SmallFont = &text.GoTextFace{
+		Source: tt,
+		Size:   smallFontSize,
+	}
+
 	NormalFont = &text.GoTextFace{
 		Source: tt,
 		Size:   normalFontSize,
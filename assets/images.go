@@ -5,17 +5,20 @@
 package assets
 
 import (
+	"bytes"
+	_ "embed"
+	"image"
+	_ "image/png"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 )
 
-// Asset file paths.
-const (
-	// logoImagePath is the relative path to the application logo image.
-	logoImagePath = "assets/static/gonnectmax_logo.png"
-)
+// logoPNG is the application logo, embedded so that it loads whatever the
+// working directory (the one of the tests included).
+//
+//go:embed static/gonnectmax_logo.png
+var logoPNG []byte
 
 // Logo is the application logo displayed on the start screen.
 //
@@ -27,9 +30,9 @@ var Logo *ebiten.Image
 // The logo image is loaded at startup and stored in a shared variable
 // to avoid reloading it multiple times during the game.
 func init() {
-	var err error
-	Logo, _, err = ebitenutil.NewImageFromFile(logoImagePath)
+	img, _, err := image.Decode(bytes.NewReader(logoPNG))
 	if err != nil {
-		log.Fatalf("failed to load logo image from %s: %v", logoImagePath, err)
+		log.Fatalf("failed to load logo image: %v", err)
 	}
+	Logo = ebiten.NewImageFromImage(img)
 }
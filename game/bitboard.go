@@ -0,0 +1,416 @@
+package game
+
+import (
+	"errors"
+	"math/bits"
+	"sync"
+)
+
+// MaxBitboardCells is the largest number of cells a Bitboard can hold.
+// It covers every board up to 8x8, the largest size offered by the setup screen.
+const MaxBitboardCells = 64
+
+// ErrBoardTooLarge is returned when a board does not fit in a Bitboard.
+var ErrBoardTooLarge = errors.New("game: board too large for a bitboard")
+
+// Bitboard is a compact board position designed for fast game tree search.
+//
+// Each player owns one 64-bit set where bit i is set if the player has a mark
+// on cell i, with i = y*Width + x. Win masks (one bitmask per possible winning
+// line) are precomputed once per (Width, Height, ToWin) and shared between
+// bitboards, so making and unmaking a move is a single bit operation and a
+// win check only looks at the lines through the played cell.
+//
+// Cells that are not open on the source board (see CellMask) are never
+// empty and no winning line goes through them.
+type Bitboard struct {
+	Width   int  // Number of columns
+	Height  int  // Number of rows
+	ToWin   int  // Required consecutive symbols to win (already clamped)
+	Gravity bool // Marks fall down their column (see Board.Gravity)
+	Wrap    bool // Lines continue across the edges (see Board.Wrap)
+
+	players []uint64  // One bitset per player, indexed like the players slice
+	blocked uint64    // Cells that cannot be played
+	masks   *winMasks // Shared precomputed win masks
+}
+
+// winMasks holds the precomputed winning lines of a board geometry.
+type winMasks struct {
+	all    []uint64   // Every winning line
+	byCell [][]uint64 // Winning lines containing each cell
+	full   uint64     // Mask of all cells of the board
+}
+
+// maskKey identifies a board geometry in the win mask cache.
+type maskKey struct {
+	width, height, toWin int
+	wrap                 bool
+}
+
+// maskCache shares win masks between bitboards of the same geometry.
+var maskCache sync.Map // map[maskKey]*winMasks
+
+// NewBitboard creates an empty bitboard for playerCount players.
+//
+// ToWin is clamped like Board does. Returns ErrBoardTooLarge if the board
+// has more than MaxBitboardCells cells.
+func NewBitboard(width, height, toWin, playerCount int) (*Bitboard, error) {
+	if width <= 0 || height <= 0 || width*height > MaxBitboardCells {
+		return nil, ErrBoardTooLarge
+	}
+
+	toWin = (&Board{Width: width, Height: height, ToWin: toWin}).effectiveToWin()
+
+	return &Bitboard{
+		Width:   width,
+		Height:  height,
+		ToWin:   toWin,
+		players: make([]uint64, playerCount),
+		masks:   loadWinMasks(width, height, toWin, false),
+	}, nil
+}
+
+// NewBitboardWithLines creates an empty bitboard whose winning lines are
+// given explicitly as lists of cells, for geometries that are not the rows,
+// columns and diagonals of the board (e.g. a Board3D stored flat).
+//
+// ToWin is set to the length of the longest line. Returns ErrBoardTooLarge
+// if the board has more than MaxBitboardCells cells.
+func NewBitboardWithLines(width, height int, lines [][]Move, playerCount int) (*Bitboard, error) {
+	if width <= 0 || height <= 0 || width*height > MaxBitboardCells {
+		return nil, ErrBoardTooLarge
+	}
+
+	bb := &Bitboard{
+		Width:   width,
+		Height:  height,
+		players: make([]uint64, playerCount),
+		masks:   &winMasks{byCell: make([][]uint64, width*height)},
+	}
+	for cell := 0; cell < width*height; cell++ {
+		bb.masks.full |= 1 << cell
+	}
+
+	for _, line := range lines {
+		var m uint64
+		for _, c := range line {
+			m |= 1 << bb.Index(c.X, c.Y)
+		}
+		bb.masks.all = append(bb.masks.all, m)
+		bb.ToWin = max(bb.ToWin, len(line))
+	}
+	bb.masks.indexByCell()
+	return bb, nil
+}
+
+// BitboardFromBoard converts a Board into a Bitboard.
+//
+// Player indices in the bitboard follow the order of the players slice.
+// Cells owned by a player missing from the slice are rejected.
+func BitboardFromBoard(b *Board, players []*Player) (*Bitboard, error) {
+	bb, err := NewBitboard(b.Width, b.Height, b.ToWin, len(players))
+	if err != nil {
+		return nil, err
+	}
+	bb.Gravity = b.Gravity
+	if b.Wrap {
+		bb.Wrap = true
+		bb.masks = loadWinMasks(bb.Width, bb.Height, bb.ToWin, true)
+	}
+	return bb, bb.load(b, players)
+}
+
+// BitboardFromBoardWithLines converts a Board into a Bitboard using the
+// given winning lines (see NewBitboardWithLines).
+func BitboardFromBoardWithLines(b *Board, players []*Player, lines [][]Move) (*Bitboard, error) {
+	bb, err := NewBitboardWithLines(b.Width, b.Height, lines, len(players))
+	if err != nil {
+		return nil, err
+	}
+	return bb, bb.load(b, players)
+}
+
+// load copies the marks and the mask of a board into the empty bitboard.
+func (bb *Bitboard) load(b *Board, players []*Player) error {
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			if b.MaskAt(x, y) != CellOpen {
+				bb.blocked |= 1 << bb.Index(x, y)
+			}
+		}
+	}
+	if bb.blocked != 0 {
+		bb.masks = bb.masks.without(bb.blocked)
+	}
+
+	index := make(map[*Player]int, len(players))
+	for i, p := range players {
+		index[p] = i
+	}
+
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			owner := b.Cells[x][y]
+			if owner == nil {
+				continue
+			}
+			i, ok := index[owner]
+			if !ok {
+				return errors.New("game: board contains an unknown player")
+			}
+			bb.Play(i, bb.Index(x, y))
+		}
+	}
+	return nil
+}
+
+// ToBoard converts the bitboard back into a Board using the given players,
+// which must be in the same order as when the bitboard was built.
+func (bb *Bitboard) ToBoard(players []*Player) *Board {
+	b := NewBoard(bb.Width, bb.Height, bb.ToWin)
+	b.Gravity = bb.Gravity
+	b.Wrap = bb.Wrap
+	for i, set := range bb.players {
+		if i >= len(players) {
+			break
+		}
+		for set != 0 {
+			cell := bits.TrailingZeros64(set)
+			set &= set - 1
+			x, y := bb.Coords(cell)
+			b.Cells[x][y] = players[i]
+		}
+	}
+	for set := bb.blocked; set != 0; set &= set - 1 {
+		x, y := bb.Coords(bits.TrailingZeros64(set))
+		b.SetCellMask(x, y, CellBlocked)
+	}
+	return b
+}
+
+// Clone returns an independent copy of the bitboard.
+func (bb *Bitboard) Clone() *Bitboard {
+	clone := *bb
+	clone.players = append([]uint64(nil), bb.players...)
+	return &clone
+}
+
+// Index returns the bit index of cell (x, y).
+func (bb *Bitboard) Index(x, y int) int {
+	return y*bb.Width + x
+}
+
+// Coords returns the (x, y) coordinates of a bit index.
+func (bb *Bitboard) Coords(cell int) (int, int) {
+	return cell % bb.Width, cell / bb.Width
+}
+
+// Neighbor returns the cell reached from cell by the step (dx, dy), across
+// the edges when the board wraps. The boolean is false if the step leaves
+// the board.
+func (bb *Bitboard) Neighbor(cell, dx, dy int) (int, bool) {
+	x, y := bb.Coords(cell)
+	x, y, ok := (&Board{Width: bb.Width, Height: bb.Height, Wrap: bb.Wrap}).wrap(x+dx, y+dy)
+	if !ok {
+		return 0, false
+	}
+	return bb.Index(x, y), true
+}
+
+// PlayerCount returns the number of players tracked by the bitboard.
+func (bb *Bitboard) PlayerCount() int {
+	return len(bb.players)
+}
+
+// Bits returns the bitset of the given player.
+func (bb *Bitboard) Bits(player int) uint64 {
+	return bb.players[player]
+}
+
+// Play places a mark of player on cell. The cell must be empty.
+func (bb *Bitboard) Play(player, cell int) {
+	bb.players[player] |= 1 << cell
+}
+
+// Unplay removes the mark of player from cell, undoing Play.
+func (bb *Bitboard) Unplay(player, cell int) {
+	bb.players[player] &^= 1 << cell
+}
+
+// Occupied returns the set of non-empty cells.
+func (bb *Bitboard) Occupied() uint64 {
+	var occ uint64
+	for _, set := range bb.players {
+		occ |= set
+	}
+	return occ
+}
+
+// Empty returns the set of empty cells.
+func (bb *Bitboard) Empty() uint64 {
+	return bb.masks.full &^ bb.Occupied()
+}
+
+// Moves returns the set of cells where the next mark may be placed:
+// every empty cell, or with Gravity the cell of each column where a mark
+// dropped from the top lands (see Board.DropRow).
+func (bb *Bitboard) Moves() uint64 {
+	empty := bb.Empty()
+	if !bb.Gravity {
+		return empty
+	}
+
+	var moves uint64
+	for x := 0; x < bb.Width; x++ {
+		var landing uint64
+		for y := 0; y < bb.Height; y++ {
+			bit := uint64(1) << bb.Index(x, y)
+			if empty&bit == 0 {
+				break
+			}
+			landing = bit
+		}
+		moves |= landing
+	}
+	return moves
+}
+
+// IsFull returns true if no empty cell remains.
+func (bb *Bitboard) IsFull() bool {
+	return bb.Empty() == 0
+}
+
+// WinsAt returns true if player owns a complete line through cell.
+// It is the bitboard counterpart of Board.CheckWinAt.
+func (bb *Bitboard) WinsAt(player, cell int) bool {
+	return bb.CompletesLineAt(bb.players[player], cell)
+}
+
+// CompletesLineAt returns true if the marks of set form a complete line
+// through cell, e.g. the marks of several teammates together.
+func (bb *Bitboard) CompletesLineAt(set uint64, cell int) bool {
+	for _, m := range bb.masks.byCell[cell] {
+		if set&m == m {
+			return true
+		}
+	}
+	return false
+}
+
+// LinesAt returns the number of complete lines of the marks of set through
+// cell. It is the bitboard counterpart of Board.LinesThrough.
+func (bb *Bitboard) LinesAt(set uint64, cell int) int {
+	n := 0
+	for _, m := range bb.masks.byCell[cell] {
+		if set&m == m {
+			n++
+		}
+	}
+	return n
+}
+
+// Winner returns the index of a player owning a complete line,
+// or -1 if there is none.
+func (bb *Bitboard) Winner() int {
+	for i, set := range bb.players {
+		for _, m := range bb.masks.all {
+			if set&m == m {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// LineCount returns the number of winning lines going through cell.
+func (bb *Bitboard) LineCount(cell int) int {
+	return len(bb.masks.byCell[cell])
+}
+
+// Lines returns every winning line mask of the board geometry.
+// The returned slice is shared and must not be modified.
+func (bb *Bitboard) Lines() []uint64 {
+	return bb.masks.all
+}
+
+// loadWinMasks returns the cached win masks for a geometry, computing them on first use.
+func loadWinMasks(width, height, toWin int, wrap bool) *winMasks {
+	key := maskKey{width: width, height: height, toWin: toWin, wrap: wrap}
+	if cached, ok := maskCache.Load(key); ok {
+		if masks, ok := cached.(*winMasks); ok {
+			return masks
+		}
+	}
+
+	masks := computeWinMasks(width, height, toWin, wrap)
+	actual, _ := maskCache.LoadOrStore(key, masks)
+	if shared, ok := actual.(*winMasks); ok {
+		return shared
+	}
+	return masks
+}
+
+// computeWinMasks enumerates every line of toWin cells in winDirections,
+// including the lines going across the edges when wrap is set.
+func computeWinMasks(width, height, toWin int, wrap bool) *winMasks {
+	ref := &Board{Width: width, Height: height, Wrap: wrap}
+	masks := &winMasks{byCell: make([][]uint64, width*height)}
+
+	for cell := 0; cell < width*height; cell++ {
+		masks.full |= 1 << cell
+	}
+
+	seen := make(map[uint64]bool)
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			for _, dir := range winDirections {
+				var m uint64
+				for step := 0; step < toWin; step++ {
+					cx, cy, ok := ref.wrap(x+dir.DX*step, y+dir.DY*step)
+					if !ok {
+						m = 0
+						break
+					}
+					m |= 1 << (cy*width + cx)
+				}
+
+				// A wrapped line may meet itself, or be found from several starts
+				if bits.OnesCount64(m) != toWin || seen[m] {
+					continue
+				}
+				seen[m] = true
+				masks.all = append(masks.all, m)
+			}
+		}
+	}
+
+	masks.indexByCell()
+	return masks
+}
+
+// without returns a copy of the masks where the blocked cells are no longer
+// part of the board and the lines going through them are dropped.
+func (masks *winMasks) without(blocked uint64) *winMasks {
+	filtered := &winMasks{
+		byCell: make([][]uint64, len(masks.byCell)),
+		full:   masks.full &^ blocked,
+	}
+	for _, m := range masks.all {
+		if m&blocked == 0 {
+			filtered.all = append(filtered.all, m)
+		}
+	}
+	filtered.indexByCell()
+	return filtered
+}
+
+// indexByCell fills byCell from the list of all lines.
+func (masks *winMasks) indexByCell() {
+	for _, m := range masks.all {
+		for set := m; set != 0; set &= set - 1 {
+			cell := bits.TrailingZeros64(set)
+			masks.byCell[cell] = append(masks.byCell[cell], m)
+		}
+	}
+}
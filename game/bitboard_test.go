@@ -0,0 +1,175 @@
+package game
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// boardFromRows returns a board drawn row by row from the top: "X" and "O"
+// are the marks of the first and second player, "#" an obstacle and "."
+// an empty cell.
+func boardFromRows(toWin int, players []*Player, rows ...string) *Board {
+	b := NewBoard(len(rows[0]), len(rows), toWin)
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'X':
+				b.Cells[x][y] = players[0]
+			case 'O':
+				b.Cells[x][y] = players[1]
+			case '#':
+				b.SetCellMask(x, y, CellBlocked)
+			}
+		}
+	}
+	return b
+}
+
+// checkBitboardWins compares the wins found on b and on its bitboard.
+func checkBitboardWins(t *testing.T, b *Board, players []*Player) {
+	t.Helper()
+	bb, err := BitboardFromBoard(b, players)
+	if err != nil {
+		t.Fatalf("BitboardFromBoard() error = %v", err)
+	}
+
+	if got, want := bb.Winner() >= 0, b.FindWin().Winner != nil; got != want {
+		t.Errorf("Winner() found a line: %v, FindWin(): %v", got, want)
+	}
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			owner := b.Cells[x][y]
+			if owner == nil {
+				continue
+			}
+			player := 0
+			if owner == players[1] {
+				player = 1
+			}
+			if got, want := bb.WinsAt(player, bb.Index(x, y)), b.CheckWinAt(x, y).Winner != nil; got != want {
+				t.Errorf("WinsAt(%d, %d) = %v, CheckWinAt() found a line: %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestBitboardMatchesBoard(t *testing.T) {
+	tests := []struct {
+		name  string
+		toWin int
+		wrap  bool
+		rows  []string
+		win   bool // A player has a line
+	}{
+		{name: "empty", toWin: 3, rows: []string{"...", "...", "..."}},
+		{name: "row", win: true, toWin: 3, rows: []string{"XXX", "OO.", "..."}},
+		{name: "column", win: true, toWin: 3, rows: []string{"XO.", "XO.", ".O."}},
+		{name: "diagonal", win: true, toWin: 3, rows: []string{"XO.", "OX.", "..X"}},
+		{name: "anti-diagonal", win: true, toWin: 3, rows: []string{"..O", "XO.", "OX."}},
+		{name: "no line", toWin: 3, rows: []string{"XOX", "XOO", "OXX"}},
+		{name: "four on a wide board", win: true, toWin: 4, rows: []string{"......", ".XXXX.", "OOO...", "......"}},
+		{name: "three of four", toWin: 4, rows: []string{"......", ".XXX..", "OOO...", "......"}},
+		{name: "obstacle breaks the line", toWin: 3, rows: []string{"X#XX", "OO..", "...."}},
+		{name: "line beside an obstacle", win: true, toWin: 3, rows: []string{"#XXX", "OO..", "...."}},
+		{name: "across the edge", win: true, toWin: 3, wrap: true, rows: []string{"X.XX", "OO..", "....", "...."}},
+		{name: "diagonal across the edges", win: true, toWin: 3, wrap: true, rows: []string{"X...", "OO..", "..X.", "...X"}},
+		{name: "no wrap without the option", toWin: 3, rows: []string{"X.XX", "OO..", "....", "...."}},
+		{name: "clamped win length", win: true, toWin: 5, rows: []string{"XXX", "OO.", "..."}},
+		{name: "8x8", win: true, toWin: 5, rows: []string{
+			"........",
+			"..X.....",
+			"...X....",
+			"....X...",
+			".....X..",
+			"......X.",
+			"OOOO....",
+			"........",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := newTestPlayers(2)
+			b := boardFromRows(tt.toWin, players, tt.rows...)
+			b.Wrap = tt.wrap
+			if got := b.FindWin().Winner != nil; got != tt.win {
+				t.Fatalf("FindWin() found a line: %v, want %v", got, tt.win)
+			}
+			checkBitboardWins(t, b, players)
+		})
+	}
+}
+
+func TestBitboardMatchesBoardOnRandomPositions(t *testing.T) {
+	tests := []struct {
+		width, height, toWin int
+		wrap                 bool
+	}{
+		{width: 3, height: 3, toWin: 3},
+		{width: 4, height: 4, toWin: 3},
+		{width: 5, height: 4, toWin: 4},
+		{width: 7, height: 6, toWin: 4},
+		{width: 8, height: 8, toWin: 5},
+		{width: 5, height: 5, toWin: 3, wrap: true},
+		{width: 6, height: 4, toWin: 4, wrap: true},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	players := newTestPlayers(2)
+	for _, tt := range tests {
+		for range 200 {
+			b := NewBoard(tt.width, tt.height, tt.toWin)
+			b.Wrap = tt.wrap
+			for x := range b.Cells {
+				for y := range b.Cells[x] {
+					switch r.Intn(5) {
+					case 0, 1:
+						b.Cells[x][y] = players[0]
+					case 2, 3:
+						b.Cells[x][y] = players[1]
+					}
+				}
+			}
+			checkBitboardWins(t, b, players)
+		}
+	}
+}
+
+func TestGravityLandsOnObstacles(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		moves []Move // Landing cell of each column that accepts a mark
+	}{
+		{name: "obstacles and marks", rows: []string{"...", ".#.", "..X", "..O"}, moves: []Move{{X: 0, Y: 3}, {X: 1, Y: 0}, {X: 2, Y: 1}}},
+		{name: "top cells taken", rows: []string{"X#O", "...", "..."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := newTestPlayers(2)
+			b := boardFromRows(3, players, tt.rows...)
+			b.Gravity = true
+
+			if got := b.AvailableMoves(); !slices.Equal(got, tt.moves) {
+				t.Errorf("AvailableMoves() = %v, want %v", got, tt.moves)
+			}
+			if got := b.CheckDraw(); got != (len(tt.moves) == 0) {
+				t.Errorf("CheckDraw() = %v with %d landing cells", got, len(tt.moves))
+			}
+
+			bb, err := BitboardFromBoard(b, players)
+			if err != nil {
+				t.Fatalf("BitboardFromBoard() error = %v", err)
+			}
+			var want uint64
+			for _, m := range tt.moves {
+				want |= 1 << bb.Index(m.X, m.Y)
+			}
+			if got := bb.Moves(); got != want {
+				t.Errorf("Moves() = %b, want %b", got, want)
+			}
+		})
+	}
+}
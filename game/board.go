@@ -3,17 +3,48 @@
 
 package game
 
+import "slices"
+
 // Board represents the game grid containing player tokens.
 //
 // The board uses a column-major layout where Cells[x][y] accesses
 // column x, row y. Width represents the number of columns and Height
 // the number of rows. ToWin defines how many consecutive symbols
 // are required to win (supports N-in-a-row variants).
+//
+// When Gravity is enabled (Connect Four style), a mark can only be placed
+// where it lands when dropped from the top of its column (see DropRow).
+//
+// When Wrap is enabled, the board is a torus: lines leaving the board on
+// one edge continue on the opposite edge, so they can go across the border.
+//
+// When TeamLines is enabled, the marks of teammates (see Player.Team) form
+// lines together, as if they were placed by a single player.
+//
+// Mask, laid out like Cells, marks the cells that cannot be played:
+// obstacles and cells outside of the board shape (see CellMask). Such cells
+// stay empty, so no line goes through them.
 type Board struct {
-	Cells  [][]*Player // 2D grid of player references (nil = empty cell)
-	Width  int         // Number of columns
-	Height int         // Number of rows
-	ToWin  int         // Required consecutive symbols to win
+	Cells     [][]*Player  // 2D grid of player references (nil = empty cell)
+	Mask      [][]CellMask // Playability of each cell (nil = every cell open)
+	Width     int          // Number of columns
+	Height    int          // Number of rows
+	ToWin     int          // Required consecutive symbols to win
+	Gravity   bool         // Marks fall down their column (see DropRow)
+	Wrap      bool         // Lines continue across the edges (toroidal board)
+	TeamLines bool         // Marks of teammates count together in lines
+
+	journal   []cellChange // Cell changes recorded since startJournal
+	recording bool         // True while cell changes are being recorded
+}
+
+// cellChange records a single cell assignment so it can be reverted
+// (undo) or applied again (redo).
+type cellChange struct {
+	X      int     // Column of the changed cell
+	Y      int     // Row of the changed cell
+	Before *Player // Cell content before the change
+	After  *Player // Cell content after the change
 }
 
 // Direction represents a 2D step vector (dx, dy) used for line scanning
@@ -38,6 +69,30 @@ var winDirections = [...]Direction{
 	{DX: 1, DY: -1}, // diagonal up-right (↗)
 }
 
+// neighborDirections contains the 8 steps to the cells around a cell,
+// used for captures and slides, which may go either way along a line.
+var neighborDirections = [...]Direction{
+	{DX: 1, DY: 0}, {DX: -1, DY: 0},
+	{DX: 0, DY: 1}, {DX: 0, DY: -1},
+	{DX: 1, DY: 1}, {DX: -1, DY: -1},
+	{DX: 1, DY: -1}, {DX: -1, DY: 1},
+}
+
+// anyMark stands for the marks of every player in line scans, so that
+// lines can be found whoever placed their marks (see CheckLineAt).
+var anyMark = &Player{}
+
+// WinResult describes the outcome of a win check.
+//
+// Winner is nil when no winning alignment was found; the other fields are
+// then left empty. Cells lists the ToWin aligned cells in the order they
+// appear along Direction.
+type WinResult struct {
+	Winner    *Player   // Player owning the line (nil = no winner)
+	Direction Direction // Direction of the line, taken from winDirections
+	Cells     []Move    // Coordinates of the winning cells
+}
+
 // Win detection constants.
 const (
 	// initialStreakCount is the starting count when considering the origin cell.
@@ -69,6 +124,8 @@ func NewBoard(width, height, toWin int) *Board {
 //
 // Returns true if the move was valid and the cell was empty.
 // Returns false if the coordinates are out of bounds or the cell is occupied.
+// With Gravity, (x, y) must also be the landing cell of column x (see
+// DropRow).
 func (b *Board) Play(player *Player, x, y int) bool {
 	if !b.isValidPosition(x, y) {
 		return false
@@ -76,11 +133,103 @@ func (b *Board) Play(player *Player, x, y int) bool {
 	if b.Cells[x][y] != nil {
 		return false
 	}
+	if b.Gravity && y != b.DropRow(x) {
+		return false
+	}
 
-	b.Cells[x][y] = player
+	b.set(x, y, player)
+	return true
+}
+
+// At returns the player owning cell (x, y), nil if it is empty or out of
+// bounds.
+func (b *Board) At(x, y int) *Player {
+	if !b.inBounds(x, y) {
+		return nil
+	}
+	return b.Cells[x][y]
+}
+
+// Remove empties the cell (x, y), e.g. when the rules take a mark away.
+// Returns false if the coordinates are out of bounds or the cell is empty.
+func (b *Board) Remove(x, y int) bool {
+	if !b.isValidPosition(x, y) || b.Cells[x][y] == nil {
+		return false
+	}
+
+	b.set(x, y, nil)
+	return true
+}
+
+// Slide moves the mark on (fromX, fromY) to the empty cell (x, y).
+// Returns false if either cell is out of bounds, the source is empty or
+// the target is occupied. Adjacency is checked by the rules.
+func (b *Board) Slide(fromX, fromY, x, y int) bool {
+	if !b.isValidPosition(fromX, fromY) || !b.isValidPosition(x, y) {
+		return false
+	}
+	player := b.Cells[fromX][fromY]
+	if player == nil || b.Cells[x][y] != nil {
+		return false
+	}
+
+	b.set(fromX, fromY, nil)
+	b.set(x, y, player)
 	return true
 }
 
+// SlideMoves returns every slide of a mark of player to an empty adjacent
+// cell (including diagonally), in the same cell order as AvailableMoves.
+func (b *Board) SlideMoves(player *Player) []Move {
+	var moves []Move
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			if b.Cells[x][y] != player {
+				continue
+			}
+			for _, dir := range neighborDirections {
+				nx, ny := x+dir.DX, y+dir.DY
+				if b.isValidPosition(nx, ny) && b.Cells[nx][ny] == nil {
+					moves = append(moves, NewSlide(x, y, nx, ny))
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// IsAdjacent reports whether the cells (x1, y1) and (x2, y2) are
+// neighbors, including diagonally.
+func IsAdjacent(x1, y1, x2, y2 int) bool {
+	dx, dy := x2-x1, y2-y1
+	return (dx != 0 || dy != 0) && dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1
+}
+
+// set assigns the cell (x, y) and records the change when journaling is active.
+//
+// All rule-driven cell writes go through set so that the game history can
+// revert side effects on cells other than the placed one.
+func (b *Board) set(x, y int, player *Player) {
+	if b.recording {
+		b.journal = append(b.journal, cellChange{X: x, Y: y, Before: b.Cells[x][y], After: player})
+	}
+	b.Cells[x][y] = player
+}
+
+// startJournal begins recording cell changes.
+func (b *Board) startJournal() {
+	b.journal = nil
+	b.recording = true
+}
+
+// stopJournal stops recording and returns the changes made since startJournal.
+func (b *Board) stopJournal() []cellChange {
+	changes := b.journal
+	b.journal = nil
+	b.recording = false
+	return changes
+}
+
 // CheckWin scans the board for a winning alignment of ToWin consecutive symbols.
 //
 // The algorithm iterates through each cell as a potential starting point,
@@ -90,7 +239,86 @@ func (b *Board) Play(player *Player, x, y int) bool {
 // Returns the winning player if found, or nil if no winner exists.
 // If ToWin is invalid (<= 0 or larger than the smallest board dimension),
 // it is clamped to the smallest dimension for robustness.
+//
+// Use FindWin to also get the winning line, or CheckWinAt to only scan
+// the lines through the last played cell.
 func (b *Board) CheckWin() *Player {
+	return b.FindWin().Winner
+}
+
+// CheckWinAt looks for a winning alignment passing through cell (x, y).
+//
+// Unlike CheckWin, only the lines going through the given cell are scanned,
+// which makes it suitable to check the last played move on large boards:
+// a move can only create a winning line that contains it.
+//
+// Returns a zero WinResult (nil Winner) if the cell is empty, out of bounds,
+// or not part of a winning line.
+func (b *Board) CheckWinAt(x, y int) WinResult {
+	if !b.inBounds(x, y) || b.Cells[x][y] == nil {
+		return WinResult{}
+	}
+	return b.lineAt(x, y, b.Cells[x][y])
+}
+
+// CompletesLine reports whether m, placing a mark of player or sliding a
+// mark, would complete a line through its target cell. The move is tried
+// on a clone, leaving the board unchanged. A move that cannot be played
+// completes nothing.
+func (b *Board) CompletesLine(player *Player, m Move) bool {
+	clone := b.Clone()
+	played := false
+	if src, ok := m.Source(); ok {
+		played = clone.Slide(src.X, src.Y, m.X, m.Y)
+	} else {
+		played = clone.Play(player, m.X, m.Y)
+	}
+	return played && clone.CheckWinAt(m.X, m.Y).Winner != nil
+}
+
+// CheckLineAt is the symbol-agnostic counterpart of CheckWinAt: it looks
+// for ToWin consecutive marks through cell (x, y) whoever placed them, as
+// if every mark were the same symbol (see NotaktoRules).
+//
+// The Winner of the result is the owner of (x, y).
+func (b *Board) CheckLineAt(x, y int) WinResult {
+	if !b.inBounds(x, y) || b.Cells[x][y] == nil {
+		return WinResult{}
+	}
+
+	line := b.lineAt(x, y, anyMark)
+	if line.Winner != nil {
+		line.Winner = b.Cells[x][y]
+	}
+	return line
+}
+
+// lineAt looks for ToWin consecutive marks of player through cell (x, y),
+// which is considered owned by player.
+func (b *Board) lineAt(x, y int, player *Player) WinResult {
+	return b.lineAlong(x, y, player, winDirections[:])
+}
+
+// lineAlong is lineAt restricted to the given line directions, for grids
+// whose lines do not follow winDirections (see HexBoard).
+func (b *Board) lineAlong(x, y int, player *Player, dirs []Direction) WinResult {
+	target := b.effectiveToWin()
+
+	for _, dir := range dirs {
+		back := b.countStreak(x, y, -dir.DX, -dir.DY, player, target-1)
+		forward := b.countStreak(x, y, dir.DX, dir.DY, player, target-1)
+
+		if back+initialStreakCount+forward >= target {
+			return b.lineResult(x-dir.DX*back, y-dir.DY*back, dir, player, target)
+		}
+	}
+
+	return WinResult{}
+}
+
+// FindWin scans the whole board like CheckWin but also reports
+// the direction and the cells of the winning line.
+func (b *Board) FindWin() WinResult {
 	target := b.effectiveToWin()
 
 	for x := 0; x < b.Width; x++ {
@@ -102,13 +330,170 @@ func (b *Board) CheckWin() *Player {
 
 			for _, dir := range winDirections {
 				if b.checkLineWin(x, y, dir, startPlayer, target) {
-					return startPlayer
+					return b.lineResult(x, y, dir, startPlayer, target)
+				}
+			}
+		}
+	}
+
+	return WinResult{}
+}
+
+// FindLines returns every line of ToWin marks of the same player on the
+// board. Overlapping lines are listed separately.
+func (b *Board) FindLines() []WinResult {
+	target := b.effectiveToWin()
+
+	var lines []WinResult
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			player := b.Cells[x][y]
+			if player == nil {
+				continue
+			}
+			for _, dir := range winDirections {
+				if b.checkLineWin(x, y, dir, player, target) {
+					lines = append(lines, b.lineResult(x, y, dir, player, target))
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// LinesThrough returns the lines of ToWin marks of player going through
+// (x, y). Overlapping lines count separately: six marks in a row hold two
+// lines of five. On a wrapping board, a line is listed once even if it
+// fills a whole loop of the torus.
+func (b *Board) LinesThrough(x, y int, player *Player) []WinResult {
+	target := b.effectiveToWin()
+
+	var lines []WinResult
+	for _, dir := range winDirections {
+		for back := target - 1; back >= 0; back-- {
+			sx, sy, ok := b.wrap(x-dir.DX*back, y-dir.DY*back)
+			if !ok || !b.holds(sx, sy, player) || !b.checkLineWin(sx, sy, dir, player, target) {
+				continue
+			}
+			line := b.lineResult(sx, sy, dir, player, target)
+			if !slices.ContainsFunc(lines, func(l WinResult) bool { return sameCells(l.Cells, line.Cells) }) {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// sameCells reports whether a and b hold the same cells, in any order.
+func sameCells(a, b []Move) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, c := range a {
+		if !slices.Contains(b, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindLine scans the whole board like FindWin, but ignores who placed the
+// marks (see CheckLineAt). The Winner of the result is the owner of the
+// first cell of the line.
+func (b *Board) FindLine() WinResult {
+	target := b.effectiveToWin()
+
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			if b.Cells[x][y] == nil {
+				continue
+			}
+
+			for _, dir := range winDirections {
+				if b.checkLineWin(x, y, dir, anyMark, target) {
+					line := b.lineResult(x, y, dir, anyMark, target)
+					line.Winner = b.Cells[x][y]
+					return line
 				}
 			}
 		}
 	}
 
-	return nil
+	return WinResult{}
+}
+
+// holds reports whether cell (x, y) holds a mark of player, or of a
+// teammate with TeamLines. Every mark matches anyMark.
+func (b *Board) holds(x, y int, player *Player) bool {
+	if player == anyMark {
+		return b.Cells[x][y] != nil
+	}
+	if b.TeamLines {
+		return player.SameSide(b.Cells[x][y])
+	}
+	return b.Cells[x][y] == player
+}
+
+// countStreak counts the consecutive cells owned by player when stepping
+// from (x, y) by (dx, dy), excluding the starting cell, up to limit cells.
+// On a wrapping board, the count stops when the streak loops back to (x, y).
+func (b *Board) countStreak(x, y, dx, dy int, player *Player, limit int) int {
+	count := 0
+	for step := firstStep; step <= limit; step++ {
+		nx, ny, ok := b.wrap(x+dx*step, y+dy*step)
+		if !ok || !b.holds(nx, ny, player) || (nx == x && ny == y) {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// runThrough returns the start cell and the length of the maximal run of
+// player's marks along dir that goes through (x, y), counting (x, y) as
+// owned by player.
+//
+// On a wrapping board, a run filling a whole loop of the torus is as long
+// as the loop.
+func (b *Board) runThrough(x, y int, dir Direction, player *Player) (int, int, int) {
+	limit := b.Width + b.Height
+	back := b.countStreak(x, y, -dir.DX, -dir.DY, player, limit)
+	if loop := b.loopLength(dir); loop > 0 {
+		limit = loop - initialStreakCount - back
+	}
+	forward := b.countStreak(x, y, dir.DX, dir.DY, player, limit)
+	return x - dir.DX*back, y - dir.DY*back, back + initialStreakCount + forward
+}
+
+// loopLength returns the number of steps along dir that lead back to the
+// starting cell on a wrapping board, or 0 if the board does not wrap.
+func (b *Board) loopLength(dir Direction) int {
+	if !b.Wrap {
+		return 0
+	}
+	x, y := dir.DX, dir.DY
+	for n := 1; ; n++ {
+		if x, y, _ = b.wrap(x, y); x == 0 && y == 0 {
+			return n
+		}
+		x, y = x+dir.DX, y+dir.DY
+	}
+}
+
+// lineResult builds the WinResult for a line of length cells starting at
+// (x, y). On a wrapping board, the cells are brought back onto the board.
+func (b *Board) lineResult(x, y int, dir Direction, player *Player, length int) WinResult {
+	cells := make([]Move, length)
+	for i := range cells {
+		cx, cy, _ := b.wrap(x+dir.DX*i, y+dir.DY*i)
+		cells[i] = Move{X: cx, Y: cy}
+	}
+
+	return WinResult{
+		Winner:    player,
+		Direction: dir,
+		Cells:     cells,
+	}
 }
 
 // checkLineWin checks if there are 'target' consecutive symbols starting
@@ -117,13 +502,11 @@ func (b *Board) checkLineWin(x, y int, dir Direction, player *Player, target int
 	count := initialStreakCount
 
 	for step := firstStep; step < target; step++ {
-		nx := x + dir.DX*step
-		ny := y + dir.DY*step
-
-		if !b.inBounds(nx, ny) {
+		nx, ny, ok := b.wrap(x+dir.DX*step, y+dir.DY*step)
+		if !ok {
 			return false
 		}
-		if b.Cells[nx][ny] != player {
+		if !b.holds(nx, ny, player) {
 			return false
 		}
 		count++
@@ -132,14 +515,25 @@ func (b *Board) checkLineWin(x, y int, dir Direction, player *Player, target int
 	return count == target
 }
 
-// CheckDraw returns true if the board is full (no empty cells remain).
+// CheckDraw returns true if the board is full (no empty open cells remain).
+// With Gravity, the board is full once no column accepts a mark, empty
+// cells below obstacles included.
 //
 // Note: The game loop should call CheckWin first; this method does not
 // verify whether a winner exists.
 func (b *Board) CheckDraw() bool {
+	if b.Gravity {
+		for x := 0; x < b.Width; x++ {
+			if b.DropRow(x) >= 0 {
+				return false
+			}
+		}
+		return true
+	}
+
 	for x := range b.Cells {
 		for y := range b.Cells[x] {
-			if b.Cells[x][y] == nil {
+			if b.Cells[x][y] == nil && b.MaskAt(x, y) == CellOpen {
 				return false
 			}
 		}
@@ -156,14 +550,45 @@ func (b *Board) Clear() {
 	}
 }
 
-// AvailableMoves returns a slice of all empty cell positions on the board.
+// DropRow returns the row where a mark dropped in column x would land,
+// or -1 if the column is full or out of bounds.
+//
+// The mark falls from the top of the column until it meets a mark or a
+// cell that is not open (see CellMask), and lands on top of it. The cells
+// below an obstacle can thus never be played.
+func (b *Board) DropRow(x int) int {
+	if x < 0 || x >= b.Width {
+		return -1
+	}
+	row := -1
+	for y := 0; y < b.Height && b.Cells[x][y] == nil && b.MaskAt(x, y) == CellOpen; y++ {
+		row = y
+	}
+	return row
+}
+
+// AvailableMoves returns a slice of all empty open cell positions on the board.
+//
+// With Gravity, only one move per column is listed: the cell where a mark
+// dropped in that column would land (see DropRow), columns whose top cell
+// is taken or blocked having none.
 //
 // This is primarily used by AI models to enumerate valid moves.
 func (b *Board) AvailableMoves() []Move {
+	if b.Gravity {
+		moves := make([]Move, 0, b.Width)
+		for x := 0; x < b.Width; x++ {
+			if y := b.DropRow(x); y >= 0 {
+				moves = append(moves, Move{X: x, Y: y})
+			}
+		}
+		return moves
+	}
+
 	moves := make([]Move, 0, b.Width*b.Height)
 	for x := 0; x < b.Width; x++ {
 		for y := 0; y < b.Height; y++ {
-			if b.Cells[x][y] == nil {
+			if b.Cells[x][y] == nil && b.MaskAt(x, y) == CellOpen {
 				moves = append(moves, Move{X: x, Y: y})
 			}
 		}
@@ -178,6 +603,10 @@ func (b *Board) AvailableMoves() []Move {
 // This is essential for AI algorithms like Minimax that simulate moves.
 func (b *Board) Clone() *Board {
 	clone := NewBoard(b.Width, b.Height, b.ToWin)
+	clone.Gravity = b.Gravity
+	clone.Wrap = b.Wrap
+	clone.TeamLines = b.TeamLines
+	clone.SetMask(b.Mask)
 
 	for x := 0; x < b.Width; x++ {
 		for y := 0; y < b.Height; y++ {
@@ -187,9 +616,10 @@ func (b *Board) Clone() *Board {
 	return clone
 }
 
-// isValidPosition returns true if (x, y) is within the board boundaries.
+// isValidPosition returns true if (x, y) is an open cell within the board
+// boundaries.
 func (b *Board) isValidPosition(x, y int) bool {
-	return b.inBounds(x, y)
+	return b.inBounds(x, y) && b.MaskAt(x, y) == CellOpen
 }
 
 // inBounds returns true if (x, y) is within the board limits.
@@ -197,6 +627,17 @@ func (b *Board) inBounds(x, y int) bool {
 	return x >= 0 && y >= 0 && x < b.Width && y < b.Height
 }
 
+// wrap returns the board cell reached at (x, y), brought back across the
+// edges when the board wraps. The boolean is false if the cell is outside
+// the board.
+func (b *Board) wrap(x, y int) (int, int, bool) {
+	if b.Wrap && b.Width > 0 && b.Height > 0 {
+		x = ((x % b.Width) + b.Width) % b.Width
+		y = ((y % b.Height) + b.Height) % b.Height
+	}
+	return x, y, b.inBounds(x, y)
+}
+
 // effectiveToWin returns a valid, clamped value for ToWin.
 //
 // If ToWin is not usable (<=0 or exceeds the smallest dimension),
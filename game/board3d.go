@@ -0,0 +1,319 @@
+package game
+
+// Direction3D represents a 3D step vector (dx, dy, dz) used for line
+// scanning in a Board3D.
+type Direction3D struct {
+	DX int // Column step (-1, 0, or 1)
+	DY int // Row step (-1, 0, or 1)
+	DZ int // Layer step (-1, 0, or 1)
+}
+
+// winDirections3D contains the 13 directions to scan for winning alignments
+// in 3D: one of each pair of opposite non-zero steps.
+var winDirections3D = [...]Direction3D{
+	// Within a layer (same as winDirections)
+	{DX: 1, DY: 0, DZ: 0},
+	{DX: 0, DY: 1, DZ: 0},
+	{DX: 1, DY: 1, DZ: 0},
+	{DX: 1, DY: -1, DZ: 0},
+
+	// Straight through the layers
+	{DX: 0, DY: 0, DZ: 1},
+
+	// Diagonals across layers along one axis
+	{DX: 1, DY: 0, DZ: 1},
+	{DX: 1, DY: 0, DZ: -1},
+	{DX: 0, DY: 1, DZ: 1},
+	{DX: 0, DY: 1, DZ: -1},
+
+	// Space diagonals
+	{DX: 1, DY: 1, DZ: 1},
+	{DX: 1, DY: 1, DZ: -1},
+	{DX: 1, DY: -1, DZ: 1},
+	{DX: 1, DY: -1, DZ: -1},
+}
+
+// Move3D represents a cell of a Board3D.
+type Move3D struct {
+	X int // Column within the layer
+	Y int // Row within the layer
+	Z int // Layer
+}
+
+// WinResult3D describes the outcome of a win check on a Board3D.
+// It mirrors WinResult with 3D directions and cells.
+type WinResult3D struct {
+	Winner    *Player     // Player owning the line (nil if none)
+	Direction Direction3D // Direction of the line
+	Cells     []Move3D    // Aligned cells, from the start of the line
+}
+
+// Board3D is a three-dimensional view of a flat Board.
+//
+// The layers are stored side by side in the underlying Board: cell
+// (x, y, z) is the flat cell (z*Width + x, y). Moves, history, undo and
+// records therefore keep working on the flat board, and each layer can be
+// rendered as a regular 2D board. The layers may also be used as independent
+// boards, as NotaktoRules does.
+type Board3D struct {
+	Board  *Board // Flat storage of every layer
+	Width  int    // Number of columns of a layer
+	Height int    // Number of rows of a layer
+	Depth  int    // Number of layers
+	ToWin  int    // Required consecutive symbols to win
+}
+
+// NewBoard3D creates an empty width x height x depth board.
+func NewBoard3D(width, height, depth, toWin int) *Board3D {
+	return Board3DOf(NewBoard(width*depth, height, toWin), depth)
+}
+
+// Board3DOf returns the 3D view of a flat board holding depth layers.
+func Board3DOf(b *Board, depth int) *Board3D {
+	return &Board3D{
+		Board:  b,
+		Width:  b.Width / depth,
+		Height: b.Height,
+		Depth:  depth,
+		ToWin:  b.ToWin,
+	}
+}
+
+// Flatten returns the flat board coordinates of cell (x, y, z).
+func (b3 *Board3D) Flatten(x, y, z int) (int, int) {
+	return z*b3.Width + x, y
+}
+
+// Unflatten returns the 3D cell at flat board coordinates (x, y).
+func (b3 *Board3D) Unflatten(x, y int) Move3D {
+	return Move3D{X: x % b3.Width, Y: y, Z: x / b3.Width}
+}
+
+// At returns the player owning cell (x, y, z), or nil.
+func (b3 *Board3D) At(x, y, z int) *Player {
+	fx, fy := b3.Flatten(x, y, z)
+	return b3.Board.Cells[fx][fy]
+}
+
+// Layer returns layer z as a 2D board sharing its cells with the 3D board.
+func (b3 *Board3D) Layer(z int) *Board {
+	return &Board{
+		Cells:  b3.Board.Cells[z*b3.Width : (z+1)*b3.Width],
+		Width:  b3.Width,
+		Height: b3.Height,
+		ToWin:  b3.ToWin,
+	}
+}
+
+// Play places a mark for player at (x, y, z).
+// Returns false if the cell is out of bounds or already occupied.
+func (b3 *Board3D) Play(player *Player, x, y, z int) bool {
+	if !b3.inBounds(x, y, z) {
+		return false
+	}
+	fx, fy := b3.Flatten(x, y, z)
+	return b3.Board.Play(player, fx, fy)
+}
+
+// AvailableMoves returns every empty cell.
+func (b3 *Board3D) AvailableMoves() []Move3D {
+	moves := make([]Move3D, 0, b3.Width*b3.Height*b3.Depth)
+	for z := 0; z < b3.Depth; z++ {
+		for x := 0; x < b3.Width; x++ {
+			for y := 0; y < b3.Height; y++ {
+				if b3.At(x, y, z) == nil {
+					moves = append(moves, Move3D{X: x, Y: y, Z: z})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// CheckWinAt looks for a winning alignment passing through cell (x, y, z),
+// scanning the 13 directions. It is the 3D counterpart of Board.CheckWinAt.
+func (b3 *Board3D) CheckWinAt(x, y, z int) WinResult3D {
+	if !b3.inBounds(x, y, z) {
+		return WinResult3D{}
+	}
+	player := b3.At(x, y, z)
+	if player == nil {
+		return WinResult3D{}
+	}
+
+	target := b3.effectiveToWin()
+	for _, dir := range winDirections3D {
+		back := b3.countStreak(x, y, z, dir, -1, player, target-1)
+		forward := b3.countStreak(x, y, z, dir, 1, player, target-1)
+
+		if back+initialStreakCount+forward >= target {
+			start := Move3D{X: x - dir.DX*back, Y: y - dir.DY*back, Z: z - dir.DZ*back}
+			return b3.lineResult(start, dir, player, target)
+		}
+	}
+	return WinResult3D{}
+}
+
+// FindWin scans the whole board for a winning alignment.
+func (b3 *Board3D) FindWin() WinResult3D {
+	for z := 0; z < b3.Depth; z++ {
+		for x := 0; x < b3.Width; x++ {
+			for y := 0; y < b3.Height; y++ {
+				if win := b3.CheckWinAt(x, y, z); win.Winner != nil {
+					return win
+				}
+			}
+		}
+	}
+	return WinResult3D{}
+}
+
+// Lines returns every possible winning line, as flat board cells.
+func (b3 *Board3D) Lines() [][]Move {
+	target := b3.effectiveToWin()
+
+	var lines [][]Move
+	for z := 0; z < b3.Depth; z++ {
+		for x := 0; x < b3.Width; x++ {
+			for y := 0; y < b3.Height; y++ {
+				for _, dir := range winDirections3D {
+					end := Move3D{
+						X: x + dir.DX*(target-1),
+						Y: y + dir.DY*(target-1),
+						Z: z + dir.DZ*(target-1),
+					}
+					if !b3.inBounds(end.X, end.Y, end.Z) {
+						continue
+					}
+					start := Move3D{X: x, Y: y, Z: z}
+					lines = append(lines, b3.FlatLine(b3.lineResult(start, dir, nil, target)).Cells)
+				}
+			}
+		}
+	}
+	return lines
+}
+
+// FlatLine converts a 3D win result to flat board coordinates.
+//
+// The direction of the result is the flat step between consecutive cells,
+// which is not a unit vector for lines crossing layers.
+func (b3 *Board3D) FlatLine(win WinResult3D) WinResult {
+	flat := WinResult{
+		Winner:    win.Winner,
+		Direction: Direction{DX: win.Direction.DX + win.Direction.DZ*b3.Width, DY: win.Direction.DY},
+	}
+	for _, c := range win.Cells {
+		fx, fy := b3.Flatten(c.X, c.Y, c.Z)
+		flat.Cells = append(flat.Cells, Move{X: fx, Y: fy})
+	}
+	return flat
+}
+
+// countStreak counts the consecutive cells owned by player when stepping
+// from (x, y, z) along dir (sign = 1) or against it (sign = -1), excluding
+// the starting cell, up to limit cells.
+func (b3 *Board3D) countStreak(x, y, z int, dir Direction3D, sign int, player *Player, limit int) int {
+	count := 0
+	for step := firstStep; step <= limit; step++ {
+		nx := x + sign*dir.DX*step
+		ny := y + sign*dir.DY*step
+		nz := z + sign*dir.DZ*step
+
+		if !b3.inBounds(nx, ny, nz) || b3.At(nx, ny, nz) != player {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// lineResult builds the WinResult3D for a line of length cells starting at start.
+func (b3 *Board3D) lineResult(start Move3D, dir Direction3D, player *Player, length int) WinResult3D {
+	cells := make([]Move3D, length)
+	for i := range cells {
+		cells[i] = Move3D{X: start.X + dir.DX*i, Y: start.Y + dir.DY*i, Z: start.Z + dir.DZ*i}
+	}
+	return WinResult3D{Winner: player, Direction: dir, Cells: cells}
+}
+
+// inBounds returns true if (x, y, z) is within the board limits.
+func (b3 *Board3D) inBounds(x, y, z int) bool {
+	return x >= 0 && y >= 0 && z >= 0 && x < b3.Width && y < b3.Height && z < b3.Depth
+}
+
+// effectiveToWin returns ToWin clamped to the smallest dimension, like Board does.
+func (b3 *Board3D) effectiveToWin() int {
+	minDim := b3.Width
+	if b3.Height < minDim {
+		minDim = b3.Height
+	}
+	if b3.Depth < minDim {
+		minDim = b3.Depth
+	}
+
+	if b3.ToWin <= 0 || b3.ToWin > minDim {
+		return minDim
+	}
+	return b3.ToWin
+}
+
+// Qubic configuration: 4x4x4 cube, four in a row wins.
+const (
+	QubicSize  = 4 // Width, height and depth of the cube
+	QubicToWin = 4 // Four in a row
+)
+
+// Rules3D implements k-in-a-row on a Board3D stored in the game's flat
+// board, with Depth layers laid side by side. Lines may run in any of the
+// 13 directions of the cube. Gravity is not supported.
+type Rules3D struct {
+	StandardRules
+	Depth int // Number of layers of the board
+}
+
+// Board3D returns the 3D view of the game board.
+func (r Rules3D) Board3D(g *Game) *Board3D {
+	return Board3DOf(g.Board, r.Depth)
+}
+
+// LegalMoves returns every empty cell, in flat board coordinates.
+func (r Rules3D) LegalMoves(g *Game) []Move {
+	b3 := r.Board3D(g)
+
+	var moves []Move
+	for _, m := range b3.AvailableMoves() {
+		x, y := b3.Flatten(m.X, m.Y, m.Z)
+		moves = append(moves, Move{X: x, Y: y})
+	}
+	return moves
+}
+
+// ValidateMove checks that m targets an empty cell inside the board.
+func (Rules3D) ValidateMove(g *Game, m Move) error {
+	if !g.Board.isValidPosition(m.X, m.Y) {
+		return ErrOutOfBounds
+	}
+	if g.Board.Cells[m.X][m.Y] != nil {
+		return ErrCellOccupied
+	}
+	return nil
+}
+
+// Outcome checks the 13 lines through the played cell, then the draw condition.
+func (r Rules3D) Outcome(g *Game, m Move) Outcome {
+	b3 := r.Board3D(g)
+	c := b3.Unflatten(m.X, m.Y)
+	if win := b3.CheckWinAt(c.X, c.Y, c.Z); win.Winner != nil {
+		return Outcome{Over: true, Winner: win.Winner, Line: b3.FlatLine(win)}
+	}
+	if g.Board.CheckDraw() {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// WinningLines returns every line of the cube, in flat board coordinates.
+func (r Rules3D) WinningLines(g *Game) [][]Move {
+	return r.Board3D(g).Lines()
+}
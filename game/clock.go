@@ -0,0 +1,169 @@
+package game
+
+import "time"
+
+// Clock tells the current time. ChessClock reads the time through it, so
+// that it can be driven by a fake clock instead of the system one.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock reading the system time.
+type SystemClock struct{}
+
+// Now returns the current system time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// TimeControlKind selects how the players' time is counted.
+type TimeControlKind int
+
+// Available time controls.
+const (
+	TimeUnlimited   TimeControlKind = iota // No clock
+	TimeSuddenDeath                        // A fixed amount of time for the whole round
+	TimeFischer                            // Increment added after every turn
+	TimeBronstein                          // Time used in a turn given back, up to the delay
+	TimePerMove                            // A fixed amount of time for every turn, unused time being lost
+
+	TimeControlKindCount // Number of time controls
+)
+
+// TimeControl describes the time each player has to play.
+type TimeControl struct {
+	Kind  TimeControlKind // How time is counted
+	Base  time.Duration   // Time of each player for the round, or for each turn with TimePerMove
+	Bonus time.Duration   // Fischer increment or Bronstein delay
+
+	// RandomOnFlag plays a random legal move for a player out of time,
+	// instead of making them lose the round. This is meant to hand the
+	// rest of their round over to chance: sudden death never gives time
+	// back to a player out of time, so each of their later moves is played
+	// at random as soon as their turn starts. With Fischer and Bronstein,
+	// they get the increment or the delay for every later turn.
+	RandomOnFlag bool
+}
+
+// ChessClock holds the clocks of the players of a game.
+//
+// The clock of the current player runs down during their turn, all the
+// placements of a multi-placement turn included. The chess clock follows
+// the game: Update stops the clock of a player once their turn is over and
+// starts the one of the next player, adding the bonus of the time control.
+// It must be called regularly to notice flag falls, and right after every
+// move so that the mover is not charged for the time until the next call.
+type ChessClock struct {
+	Control TimeControl // Time control of the match
+	Clock   Clock       // Source of the time (nil = SystemClock)
+
+	game      *Game
+	remaining map[*Player]time.Duration // Time left at the start of the running turn
+	running   *Player                   // Player whose clock runs (nil = stopped)
+	started   time.Time                 // Time the running clock was started
+}
+
+// NewChessClock returns the clocks of the players of g, each set to the
+// base time of control.
+func NewChessClock(g *Game, control TimeControl, clock Clock) *ChessClock {
+	c := &ChessClock{Control: control, Clock: clock, game: g}
+	c.Reset()
+	return c
+}
+
+// Enabled reports whether the players' time is limited.
+func (c *ChessClock) Enabled() bool {
+	return c.Control.Kind != TimeUnlimited
+}
+
+// Reset stops the clocks and sets every player's clock back to the base
+// time, for a new round.
+func (c *ChessClock) Reset() {
+	c.remaining = map[*Player]time.Duration{}
+	for _, p := range c.game.Players {
+		c.remaining[p] = c.Control.Base
+	}
+	c.running = nil
+}
+
+// Remaining returns the time left to p, counting the running turn. It is
+// never negative. With TimePerMove, players waiting for their turn have
+// the time of a whole turn.
+func (c *ChessClock) Remaining(p *Player) time.Duration {
+	switch {
+	case p == c.running:
+		return max(c.remaining[p]-c.now().Sub(c.started), 0)
+	case c.Control.Kind == TimePerMove:
+		return c.Control.Base
+	default:
+		return c.remaining[p]
+	}
+}
+
+// Running returns the player whose clock runs, nil when the clocks are
+// stopped.
+func (c *ChessClock) Running() *Player {
+	return c.running
+}
+
+// Update switches the clocks to the player whose turn it is, stopping them
+// once the round is over. It returns the current player if their time ran
+// out, nil otherwise.
+func (c *ChessClock) Update() *Player {
+	if !c.Enabled() {
+		return nil
+	}
+
+	current := c.game.Current
+	if !c.game.IsPlaying() {
+		current = nil
+	}
+	if current != c.running {
+		now := c.now()
+		c.stop(now)
+		c.start(current, now)
+		return nil
+	}
+
+	if c.running != nil && c.Remaining(c.running) <= 0 {
+		return c.running
+	}
+	return nil
+}
+
+// stop stops the running clock, if any, and adds the bonus of the time
+// control to it. Time used past the flag fall is not taken from the bonus.
+func (c *ChessClock) stop(now time.Time) {
+	if c.running == nil {
+		return
+	}
+
+	used := now.Sub(c.started)
+	left := max(c.remaining[c.running]-used, 0)
+	switch c.Control.Kind {
+	case TimeFischer:
+		left += c.Control.Bonus
+	case TimeBronstein:
+		left += min(used, c.Control.Bonus)
+	}
+	c.remaining[c.running] = left
+	c.running = nil
+}
+
+// start starts the clock of p (nil = none). With TimePerMove, it is first
+// set back to the time of a turn.
+func (c *ChessClock) start(p *Player, now time.Time) {
+	if p != nil && c.Control.Kind == TimePerMove {
+		c.remaining[p] = c.Control.Base
+	}
+	c.running = p
+	c.started = now
+}
+
+// now returns the current time of the clock.
+func (c *ChessClock) now() time.Time {
+	if c.Clock == nil {
+		return SystemClock{}.Now()
+	}
+	return c.Clock.Now()
+}
@@ -0,0 +1,228 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestClock returns a two-player game on a board too large to be won
+// by the tests, and its chess clock, started on the first player.
+func newTestClock(t *testing.T, control TimeControl) (*Game, *ChessClock, *fakeClock) {
+	t.Helper()
+	g := newTestGame(5, 5, 5, 2)
+	fake := &fakeClock{now: time.Unix(0, 0)}
+	c := NewChessClock(g, control, fake)
+	if flagged := c.Update(); flagged != nil {
+		t.Fatalf("Update() at start = %p, want nil", flagged)
+	}
+	if c.Running() != g.Players[0] {
+		t.Fatalf("Running() at start is not the first player")
+	}
+	return g, c, fake
+}
+
+// playTimed lets d pass, plays m and updates the clock as a move is
+// handled.
+func playTimed(t *testing.T, g *Game, c *ChessClock, fake *fakeClock, d time.Duration, m Move) {
+	t.Helper()
+	fake.advance(d)
+	mustPlay(t, g, m)
+	if flagged := c.Update(); flagged != nil {
+		t.Fatalf("Update() after %v = %p, want nil", m, flagged)
+	}
+}
+
+func checkRemaining(t *testing.T, c *ChessClock, p *Player, want time.Duration) {
+	t.Helper()
+	if got := c.Remaining(p); got != want {
+		t.Errorf("Remaining() = %v, want %v", got, want)
+	}
+}
+
+func TestChessClockBonus(t *testing.T) {
+	tests := []struct {
+		name    string
+		control TimeControl
+		used    []time.Duration // Time used by the first player in each of their turns
+		want    time.Duration   // Time left to them after the turns
+	}{
+		{
+			name:    "sudden death",
+			control: TimeControl{Kind: TimeSuddenDeath, Base: 10 * time.Second},
+			used:    []time.Duration{3 * time.Second, 2 * time.Second},
+			want:    5 * time.Second,
+		},
+		{
+			name:    "Fischer",
+			control: TimeControl{Kind: TimeFischer, Base: 10 * time.Second, Bonus: 2 * time.Second},
+			used:    []time.Duration{3 * time.Second, time.Second},
+			want:    10 * time.Second,
+		},
+		{
+			name:    "Bronstein within the delay",
+			control: TimeControl{Kind: TimeBronstein, Base: 10 * time.Second, Bonus: 2 * time.Second},
+			used:    []time.Duration{time.Second, 2 * time.Second},
+			want:    10 * time.Second,
+		},
+		{
+			name:    "Bronstein delay cap",
+			control: TimeControl{Kind: TimeBronstein, Base: 10 * time.Second, Bonus: 2 * time.Second},
+			used:    []time.Duration{5 * time.Second, 3 * time.Second},
+			want:    6 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, c, fake := newTestClock(t, tt.control)
+			first, second := g.Players[0], g.Players[1]
+			for i, used := range tt.used {
+				playTimed(t, g, c, fake, used, NewMove(i, 0))
+				playTimed(t, g, c, fake, time.Second, NewMove(i, 1))
+			}
+			checkRemaining(t, c, first, tt.want)
+			if c.Running() != first {
+				t.Errorf("Running() is not the first player back on turn")
+			}
+
+			// The time of the second player only ran during their turns
+			want := tt.control.Base - time.Duration(len(tt.used))*time.Second
+			switch tt.control.Kind {
+			case TimeFischer:
+				want += time.Duration(len(tt.used)) * tt.control.Bonus
+			case TimeBronstein:
+				want = tt.control.Base
+			}
+			checkRemaining(t, c, second, want)
+		})
+	}
+}
+
+func TestChessClockSwitchesOnMove(t *testing.T) {
+	g, c, fake := newTestClock(t, TimeControl{Kind: TimeSuddenDeath, Base: 10 * time.Second})
+	first, second := g.Players[0], g.Players[1]
+
+	// Time passing after the move is charged to the next player only
+	playTimed(t, g, c, fake, 3*time.Second, NewMove(0, 0))
+	fake.advance(time.Second)
+	checkRemaining(t, c, first, 7*time.Second)
+	checkRemaining(t, c, second, 9*time.Second)
+}
+
+func TestChessClockPerMove(t *testing.T) {
+	g, c, fake := newTestClock(t, TimeControl{Kind: TimePerMove, Base: 5 * time.Second})
+	first, second := g.Players[0], g.Players[1]
+
+	fake.advance(3 * time.Second)
+	checkRemaining(t, c, first, 2*time.Second)
+	mustPlay(t, g, NewMove(0, 0))
+	c.Update()
+
+	// Unused time is lost, and every turn starts with the whole turn time
+	checkRemaining(t, c, first, 5*time.Second)
+	checkRemaining(t, c, second, 5*time.Second)
+	playTimed(t, g, c, fake, 4*time.Second, NewMove(0, 1))
+	checkRemaining(t, c, first, 5*time.Second)
+
+	fake.advance(5 * time.Second)
+	if flagged := c.Update(); flagged != first {
+		t.Errorf("Update() after the turn time did not flag the first player")
+	}
+}
+
+func TestChessClockFlagFall(t *testing.T) {
+	g, c, fake := newTestClock(t, TimeControl{Kind: TimeSuddenDeath, Base: 10 * time.Second})
+	first, second := g.Players[0], g.Players[1]
+
+	playTimed(t, g, c, fake, 4*time.Second, NewMove(0, 0))
+	fake.advance(9 * time.Second)
+	if flagged := c.Update(); flagged != nil {
+		t.Fatalf("Update() with time left flagged a player")
+	}
+	fake.advance(2 * time.Second)
+	checkRemaining(t, c, second, 0)
+	flagged := c.Update()
+	if flagged != second {
+		t.Fatalf("Update() out of time did not flag the second player")
+	}
+
+	g.TimeOut(flagged)
+	if g.State != StateGameEnd || g.Winner != first || g.Loser != second || g.TimedOut() != second {
+		t.Errorf("TimeOut() did not end the round lost on time by the second player")
+	}
+	if first.Points != 1 {
+		t.Errorf("winner points = %d, want 1", first.Points)
+	}
+
+	// The clocks stop with the round
+	if flagged := c.Update(); flagged != nil || c.Running() != nil {
+		t.Errorf("clocks still running after the round")
+	}
+}
+
+func TestChessClockRandomOnFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		control TimeControl
+		want    time.Duration // Time of the flagged player on their next turn
+	}{
+		{
+			name:    "sudden death",
+			control: TimeControl{Kind: TimeSuddenDeath, Base: 5 * time.Second, RandomOnFlag: true},
+			want:    0,
+		},
+		{
+			name:    "Fischer",
+			control: TimeControl{Kind: TimeFischer, Base: 5 * time.Second, Bonus: 2 * time.Second, RandomOnFlag: true},
+			want:    2 * time.Second,
+		},
+		{
+			name:    "Bronstein",
+			control: TimeControl{Kind: TimeBronstein, Base: 5 * time.Second, Bonus: 2 * time.Second, RandomOnFlag: true},
+			want:    2 * time.Second,
+		},
+		{
+			name:    "per move",
+			control: TimeControl{Kind: TimePerMove, Base: 5 * time.Second, RandomOnFlag: true},
+			want:    5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, c, fake := newTestClock(t, tt.control)
+			first := g.Players[0]
+
+			// The flagged player gets a forced move instead of losing
+			fake.advance(6 * time.Second)
+			flagged := c.Update()
+			if flagged != first {
+				t.Fatalf("Update() out of time did not flag the first player")
+			}
+			mustPlay(t, g, g.LegalMoves()[0])
+			c.Update()
+			if !g.IsPlaying() {
+				t.Fatalf("forced move ended the round")
+			}
+
+			playTimed(t, g, c, fake, time.Second, NewMove(4, 4))
+			checkRemaining(t, c, first, tt.want)
+			if flagged := c.Update(); (flagged == first) != (tt.want == 0) {
+				t.Errorf("Update() flag of the first player = %v, want %v", flagged == first, tt.want == 0)
+			}
+		})
+	}
+}
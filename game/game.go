@@ -3,6 +3,7 @@ package game
 import (
 	"GoTicTacToe/assets"
 	"image/color"
+	"slices"
 )
 
 // GameState represents the current phase of a game.
@@ -24,16 +25,45 @@ const (
 
 // Game orchestrates the game state, players, and board interactions.
 // It manages turn order, win/draw detection, and score tracking.
+//
+// Rule decisions are delegated to Rules; a nil Rules means StandardRules.
 type Game struct {
 	State   GameState // Current game phase (playing or ended)
 	Board   *Board    // The game board containing cell states
 	Players []*Player // All players participating in the game
 	Current *Player   // The player whose turn it currently is
 	Winner  *Player   // The winner of the current round (nil if draw or ongoing)
+	Loser   *Player   // The player who completed a line in misère (nil otherwise)
+	WinLine WinResult // Winning (or, in misère, losing) alignment of the round (zero if none)
+	Rules   Ruleset   // Rules of the variant being played (nil = StandardRules)
+	Misere  bool      // Completing a line loses the round instead of winning it
+
+	// LineScoring makes every completed line score a point for its owner
+	// instead of ending the round. The round ends once the board is full,
+	// won by the player with the most lines (see lineOutcome).
+	LineScoring bool
+
+	// MaxMarks limits the number of marks each player may hold on the board
+	// (0 = unlimited). Placing one more mark removes the player's oldest one.
+	MaxMarks int
+
+	// Schedule gives the number of marks placed in each turn (nil = one).
+	// The current player keeps playing until their placements are done.
+	Schedule TurnSchedule
+
+	first    *Player   // Player moving first in each round (nil = Players[0])
+	timedOut *Player   // Player who lost the round on time (nil otherwise)
+	store    CellStore // Marks of the round when the rules provide their board (see Store)
 
 	boardWidth  int // Configured board width for resets
 	boardHeight int // Configured board height for resets
 	toWin       int // Configured win condition for resets
+
+	turn   int // Current turn number (1-based)
+	placed int // Marks already placed in the current turn
+
+	history []historyEntry // Played moves, including undone ones that can be redone
+	cursor  int            // Number of history entries currently applied
 }
 
 // NewGame creates a new Game with default 3x3 configuration and two players.
@@ -68,11 +98,20 @@ func (g *Game) ResetHardWithPlayers(boardWidth, boardHeight, toWin int, players
 	}
 
 	g.Players = players
+	g.first = nil
+	g.timedOut = nil
+	g.store = nil
 	g.resetAllPlayerScores()
 
 	g.Current = g.Players[0]
 	g.Winner = nil
+	g.Loser = nil
+	g.WinLine = WinResult{}
 	g.State = StatePlaying
+	g.resetRoundCounts()
+	g.turn = 1
+	g.placed = 0
+	g.clearHistory()
 }
 
 // createDefaultPlayers returns the standard two-player setup.
@@ -90,8 +129,17 @@ func (g *Game) resetAllPlayerScores() {
 	}
 }
 
+// resetRoundCounts sets the captures and the lines of all players to zero.
+func (g *Game) resetRoundCounts() {
+	for _, player := range g.Players {
+		player.Captures = 0
+		player.Lines = 0
+	}
+}
+
 // Reset clears the board and restarts the game while preserving player scores.
-// Use this between rounds in a multi-round match.
+// The new round is started by FirstPlayer. Use this between rounds in a
+// multi-round match (see Match).
 func (g *Game) Reset() {
 	if g.Board == nil {
 		g.Board = NewBoard(g.boardWidth, g.boardHeight, g.toWin)
@@ -99,9 +147,39 @@ func (g *Game) Reset() {
 		g.Board.Clear()
 	}
 
-	g.Current = g.Players[0]
+	g.Current = g.FirstPlayer()
 	g.Winner = nil
+	g.Loser = nil
+	g.timedOut = nil
+	g.store = nil
+	g.WinLine = WinResult{}
 	g.State = StatePlaying
+	g.resetRoundCounts()
+	g.turn = 1
+	g.placed = 0
+	g.clearHistory()
+}
+
+// FirstPlayer returns the player who moves first in each round: the one
+// given to SetFirstPlayer, Players[0] by default.
+func (g *Game) FirstPlayer() *Player {
+	if g.first != nil {
+		return g.first
+	}
+	if len(g.Players) == 0 {
+		return nil
+	}
+	return g.Players[0]
+}
+
+// SetFirstPlayer gives the first move of the next rounds to p, one of the
+// players (nil = Players[0]). If no move has been played in the current
+// round yet, p also moves first in it.
+func (g *Game) SetFirstPlayer(p *Player) {
+	g.first = p
+	if len(g.history) == 0 && g.IsPlaying() {
+		g.Current = g.FirstPlayer()
+	}
 }
 
 // ResetPoints sets all player scores to zero without affecting the current game state.
@@ -109,67 +187,333 @@ func (g *Game) ResetPoints() {
 	g.resetAllPlayerScores()
 }
 
-// NextPlayer advances the turn to the next player in rotation.
-// Players are cycled in the order they appear in the Players slice.
+// RulesOrDefault returns the Ruleset in use, StandardRules if none is set.
+func (g *Game) RulesOrDefault() Ruleset {
+	if g.Rules == nil {
+		return StandardRules{}
+	}
+	return g.Rules
+}
+
+// NextPlayer advances the turn to the player chosen by the rules.
+// With StandardRules, players are cycled in the order they appear in the
+// Players slice.
 func (g *Game) NextPlayer() {
 	if len(g.Players) == 0 {
 		return
 	}
+	g.Current = g.RulesOrDefault().NextPlayer(g)
+}
+
+// LegalMoves lists the moves the current player may play.
+// It returns nil once the round is over.
+func (g *Game) LegalMoves() []Move {
+	if g.State != StatePlaying {
+		return nil
+	}
+	return g.RulesOrDefault().LegalMoves(g)
+}
+
+// ValidateMove returns nil if the current player may play m,
+// or an error explaining why the move is refused.
+//
+// The symbol of another player can only be placed if the rules allow it
+// (see SymbolRules).
+func (g *Game) ValidateMove(m Move) error {
+	if g.State != StatePlaying {
+		return ErrGameOver
+	}
+	if m.Mark != nil && m.Mark != g.Current && !slices.Contains(g.Marks(), m.Mark) {
+		return ErrForeignMark
+	}
+	return g.RulesOrDefault().ValidateMove(g, m)
+}
+
+// Marks returns the players whose symbols the current player may place:
+// the ones listed by SymbolRules, or only the current player otherwise.
+func (g *Game) Marks() []*Player {
+	if rules, ok := g.Rules.(SymbolRules); ok {
+		return rules.Marks(g)
+	}
+	return []*Player{g.Current}
+}
+
+// Play executes move m for the current player.
+//
+// The move is validated and applied by the rules, which then decide whether
+// the round is over and who plays next. With LineScoring, the round only
+// ends once the board is full. With a Schedule, the turn only passes once
+// the current player has placed all the marks of their turn.
+//
+// The move is recorded in the history so it can be undone. Returns the
+// validation error if the move is refused.
+func (g *Game) Play(m Move) error {
+	if err := g.ValidateMove(m); err != nil {
+		return err
+	}
+
+	rules := g.RulesOrDefault()
+	entry := historyEntry{
+		record: MoveRecord{
+			Player: g.Current, X: m.X, Y: m.Y, Turn: g.turn,
+			FromX: m.FromX, FromY: m.FromY, HasSource: m.HasSource,
+			PairX: m.PairX, PairY: m.PairY, HasPair: m.HasPair,
+		},
+		before: g.takeSnapshot(),
+	}
+	if m.Mark != g.Current {
+		entry.record.Mark = m.Mark
+	}
+
+	store := g.Store()
+	store.startJournal()
+	rules.ApplyMove(g, m)
+	g.enforceMarkLimit(entry.record.Player, m)
+	var outcome Outcome
+	if g.scoresLines() {
+		outcome = g.lineOutcome(m)
+	} else {
+		outcome = g.adjustOutcome(rules.Outcome(g, m))
+	}
+	if outcome.Over {
+		g.endRound(outcome)
+	} else {
+		g.endPlacement()
+	}
+	entry.changes = store.stopJournal()
+
+	entry.after = g.takeSnapshot()
+	g.pushHistory(entry)
+	return nil
+}
+
+// enforceMarkLimit removes the oldest marks of player, who just played m,
+// until they hold at most MaxMarks marks. Slides add no mark.
+func (g *Game) enforceMarkLimit(player *Player, m Move) {
+	if g.MaxMarks <= 0 || m.HasSource {
+		return
+	}
+
+	// The move is not in the history yet: count it as the newest mark
+	m = m.Cell()
+	marks := slices.DeleteFunc(g.MarksOf(player), func(c Move) bool { return c == m })
+	marks = append(marks, m)
+	for i := 0; len(marks)-i > g.MaxMarks; i++ {
+		g.Store().Remove(marks[i].X, marks[i].Y)
+	}
+}
 
-	for i, player := range g.Players {
-		if player == g.Current {
-			g.Current = g.Players[(i+1)%len(g.Players)]
-			return
+// MarksOf returns the cells currently holding a mark of player, from the
+// oldest to the most recently placed.
+//
+// The placement order is derived from the history, so it stays correct
+// across undo and redo. A slid mark keeps the age of its placement. The
+// marks are read on the store of the round (see Store).
+func (g *Game) MarksOf(player *Player) []Move {
+	var marks []Move
+	for _, rec := range g.History() {
+		if rec.Player != player {
+			continue
 		}
+		m := Move{X: rec.X, Y: rec.Y}
+		if src, ok := rec.Move().Source(); ok {
+			if i := slices.Index(marks, src); i >= 0 {
+				marks[i] = m
+			}
+			continue
+		}
+		// A cell can be played again after its mark was removed
+		marks = slices.DeleteFunc(marks, func(c Move) bool { return c == m })
+		marks = append(marks, m)
 	}
 
-	// Fallback: current player not found in list, reset to first
-	g.Current = g.Players[0]
+	return slices.DeleteFunc(marks, func(c Move) bool {
+		return g.Store().At(c.X, c.Y) != player
+	})
+}
+
+// VanishingMark returns the mark of the current player that will be removed
+// by their next placement when MaxMarks is reached. The boolean is false if
+// no mark is about to vanish.
+func (g *Game) VanishingMark() (Move, bool) {
+	if g.MaxMarks <= 0 || g.State != StatePlaying {
+		return Move{}, false
+	}
+	marks := g.MarksOf(g.Current)
+	if len(marks) < g.MaxMarks {
+		return Move{}, false
+	}
+	return marks[len(marks)-g.MaxMarks], true
 }
 
 // PlayMove attempts to execute a move at coordinates (x, y) for the current player.
 // Returns true if the move was valid and executed successfully.
 // After a valid move, the game checks for win/draw conditions and advances the turn.
+// Use Play to know why a move was refused.
 func (g *Game) PlayMove(x, y int) bool {
-	if !g.Board.Play(g.Current, x, y) {
+	return g.Play(NewMove(x, y)) == nil
+}
+
+// CheckWin checks if the current board state contains a winning alignment.
+// If a winner is found, updates the game state, scores the round through
+// the rules, and sets the State to StateGameEnd.
+//
+// In misère, the owner of the alignment loses the round instead and the
+// other players are awarded the points.
+func (g *Game) CheckWin() bool {
+	win := g.Store().FindWin()
+	if win.Winner == nil {
 		return false
 	}
 
-	if g.CheckWin() {
-		return true
+	g.endRound(g.adjustOutcome(Outcome{Over: true, Winner: win.Winner, Line: win}))
+	return true
+}
+
+// adjustOutcome applies the game options to an outcome computed by the rules.
+//
+// In misère, the player who completed the line becomes the loser. With two
+// players the opponent is the winner; with more, the round has no single
+// winner and every other player is rewarded by Ruleset.Score.
+//
+// Outcomes naming only a loser are inverted on purpose, whatever the rules
+// reporting them: the loser wins the round alone. Misère Notakto with more
+// than two players is thus won by whoever kills the last board, and misère
+// Order and Chaos with several Chaos players by Order once the board fills
+// up, like with two players. Rounds lost on time (see TimeOut) do not go
+// through adjustOutcome and are never inverted.
+func (g *Game) adjustOutcome(o Outcome) Outcome {
+	if !g.Misere || (o.Winner == nil && o.Loser == nil) {
+		return o
+	}
+	if o.Winner == nil {
+		o.Winner, o.Loser = o.Loser, nil
+		return o
 	}
 
-	if g.CheckDraw() {
-		return true
+	o.Loser = o.Winner
+	o.Winner = nil
+	if opponents := o.Loser.Opponents(g.Players); len(opponents) == 1 {
+		o.Winner = opponents[0]
 	}
+	return o
+}
 
-	g.NextPlayer()
-	return true
+// lineOutcome scores the lines completed by move m for the owner of the
+// mark it placed, then ends the round if the board is full. The player with
+// the most lines wins the round, the one with the fewest in misère; a tie
+// for the best total is a draw. Teammates add up their lines, the round
+// being won by the first player of the best team.
+func (g *Game) lineOutcome(m Move) Outcome {
+	if g.Board.inBounds(m.X, m.Y) {
+		if owner := g.Board.Cells[m.X][m.Y]; owner != nil {
+			owner.Lines += len(g.Board.LinesThrough(m.X, m.Y, owner))
+		}
+	}
+	if !g.Board.CheckDraw() {
+		return Outcome{}
+	}
+
+	var best *Player
+	tie := false
+	for _, p := range g.Players {
+		lines, bestLines := g.teamTotal(p, linesOf), g.teamTotal(best, linesOf)
+		switch {
+		case best != nil && best.SameSide(p):
+			continue
+		case best != nil && lines == bestLines:
+			tie = true
+		// In misère, fewer lines is better
+		case best == nil || (lines > bestLines) != g.Misere:
+			best, tie = p, false
+		}
+	}
+	if tie {
+		return Outcome{Over: true}
+	}
+	return Outcome{Over: true, Winner: best}
 }
 
-// CheckWin checks if the current board state contains a winning alignment.
-// If a winner is found, updates the game state, increments their score,
-// and sets the State to StateGameEnd.
-func (g *Game) CheckWin() bool {
-	winner := g.Board.CheckWin()
-	if winner == nil {
-		return false
+// TeamPoints returns the points of p and their teammates together.
+func (g *Game) TeamPoints(p *Player) int {
+	return g.teamTotal(p, func(q *Player) int { return q.Points })
+}
+
+// teamTotal adds up value over p and their teammates (0 for a nil p).
+func (g *Game) teamTotal(p *Player, value func(*Player) int) int {
+	if p == nil {
+		return 0
+	}
+	total := 0
+	for _, q := range g.Players {
+		if p.SameSide(q) {
+			total += value(q)
+		}
+	}
+	return total
+}
+
+// scoresLines reports whether completed lines score points (LineScoring).
+// Lines are counted on the game Board, so rules providing their own board
+// (see BoardProvider) ignore the option.
+func (g *Game) scoresLines() bool {
+	_, provided := g.Rules.(BoardProvider)
+	return g.LineScoring && !provided
+}
+
+// linesOf returns the lines completed by p in the current round.
+func linesOf(p *Player) int {
+	return p.Lines
+}
+
+// TimeOut ends the round, lost by p whose time ran out (see ChessClock).
+// With a single opposing side, its first player wins the round; with more,
+// every opponent is rewarded by Ruleset.Score, as in misère. Nothing
+// happens once the round is over.
+func (g *Game) TimeOut(p *Player) {
+	if !g.IsPlaying() || p == nil {
+		return
+	}
+
+	o := Outcome{Over: true, Loser: p}
+	if opponents := p.Opponents(g.Players); len(opponents) > 0 {
+		o.Winner = opponents[0]
+		for _, q := range opponents {
+			if !o.Winner.SameSide(q) {
+				o.Winner = nil
+				break
+			}
+		}
 	}
+	g.timedOut = p
+	g.endRound(o)
+}
+
+// TimedOut returns the player who lost the round on time, nil if the round
+// did not end with a time out.
+func (g *Game) TimedOut() *Player {
+	return g.timedOut
+}
 
-	g.Winner = winner
-	g.Winner.Points++
+// endRound ends the current round with the given outcome and scores it.
+func (g *Game) endRound(o Outcome) {
+	g.Winner = o.Winner
+	g.Loser = o.Loser
+	g.WinLine = o.Line
 	g.State = StateGameEnd
-	return true
+	g.RulesOrDefault().Score(g, o)
 }
 
 // CheckDraw checks if the game is a draw (board full with no winner).
 // If a draw is detected, sets the State to StateGameEnd with no winner.
+// The board is the store of the round (see Store).
 func (g *Game) CheckDraw() bool {
-	if !g.Board.CheckDraw() {
+	if !g.Store().CheckDraw() {
 		return false
 	}
 
 	g.Winner = nil
+	g.Loser = nil
 	g.State = StateGameEnd
 	return true
 }
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMisereInvertsLoserOnlyOutcomes(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Ruleset
+		players int
+		moves   func(g *Game) []Move
+		loser   int // Index of the player losing the round without misère
+	}{
+		{
+			// The third player completes the first row, killing the only board.
+			name:    "notakto of three",
+			rules:   NotaktoRules{},
+			players: 3,
+			moves: func(*Game) []Move {
+				return []Move{NewMove(0, 0), NewMove(1, 0), NewMove(2, 0)}
+			},
+			loser: 2,
+		},
+		{
+			// The board fills up without a line: Order loses to two Chaos players.
+			name:    "order and chaos with two chaos players",
+			rules:   OrderChaosRules{},
+			players: 3,
+			moves: func(g *Game) []Move {
+				x, o := g.Players[0], g.Players[1]
+				return []Move{
+					NewMove(0, 0).WithMark(x), NewMove(1, 0).WithMark(o), NewMove(2, 0).WithMark(x),
+					NewMove(0, 1).WithMark(x), NewMove(1, 1).WithMark(o), NewMove(2, 1).WithMark(o),
+					NewMove(0, 2).WithMark(o), NewMove(1, 2).WithMark(x), NewMove(2, 2).WithMark(x),
+				}
+			},
+			loser: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, misere := range []bool{false, true} {
+			g := newTestGame(3, 3, 3, tt.players)
+			g.Rules = tt.rules
+			g.Misere = misere
+			mustPlay(t, g, tt.moves(g)...)
+
+			// Without misère, every other player scores against the loser;
+			// in misère, the loser of the rules wins the round alone.
+			want := make([]int, len(g.Players))
+			wantWinner, wantLoser := -1, tt.loser
+			if misere {
+				want[tt.loser] = 1
+				wantWinner, wantLoser = tt.loser, -1
+			} else {
+				for i := range want {
+					want[i] = 1
+				}
+				want[tt.loser] = 0
+			}
+
+			points := make([]int, len(g.Players))
+			for i, p := range g.Players {
+				points[i] = p.Points
+			}
+			winner, loser := slices.Index(g.Players, g.Winner), slices.Index(g.Players, g.Loser)
+			if g.State != StateGameEnd || winner != wantWinner || loser != wantLoser || !slices.Equal(points, want) {
+				t.Errorf("%s, misère %v: state %v, winner %d, loser %d, points %v, want winner %d, loser %d, points %v",
+					tt.name, misere, g.State, winner, loser, points, wantWinner, wantLoser, want)
+			}
+		}
+	}
+}
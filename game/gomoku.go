@@ -0,0 +1,246 @@
+package game
+
+import "errors"
+
+// GomokuVariant selects one of the Gomoku rule sets.
+type GomokuVariant int
+
+const (
+	// GomokuFreestyle: five or more in a row wins.
+	GomokuFreestyle GomokuVariant = iota
+
+	// GomokuStandard: exactly five in a row wins, overlines do not win.
+	GomokuStandard
+
+	// GomokuRenju: like standard, but the first player may not play
+	// double-threes, double-fours or overlines. The second player wins
+	// with five or more.
+	GomokuRenju
+)
+
+// Default Gomoku board configuration.
+const (
+	GomokuBoardSize = 15 // Gomoku is played on a 15x15 board
+	GomokuToWin     = 5  // Five in a row
+)
+
+// Forbidden move errors reported by GomokuRules.ValidateMove in Renju.
+var (
+	// ErrOverline is returned when the first player would make more than five in a row.
+	ErrOverline = errors.New("game: overline is forbidden for the first player")
+
+	// ErrDoubleFour is returned when the first player would make two fours at once.
+	ErrDoubleFour = errors.New("game: double-four is forbidden for the first player")
+
+	// ErrDoubleThree is returned when the first player would make two open threes at once.
+	ErrDoubleThree = errors.New("game: double-three is forbidden for the first player")
+)
+
+// Pattern counting constants.
+const (
+	// straightFourGap is the distance between the two completion cells of a
+	// straight (open) four, e.g. the two ends of ".XXXX.".
+	straightFourGap = 5
+
+	// maxFoursPerLine caps the number of fours counted along a single line.
+	maxFoursPerLine = 2
+)
+
+// GomokuRules implements Gomoku and Renju on top of StandardRules.
+//
+// Lines are measured as maximal runs through the played cell, so that
+// overlines (runs longer than Board.ToWin) can be told apart from exact
+// lines. In Renju, the player moving first (see Game.FirstPlayer) is the
+// restricted one.
+//
+// The open-three detection does not check whether the move turning a three
+// into a straight four would itself be forbidden, which is the usual
+// simplification of the full Renju definition.
+type GomokuRules struct {
+	StandardRules
+	Variant GomokuVariant // Rule set in use
+}
+
+// LegalMoves returns every empty cell, minus the forbidden ones in Renju.
+func (r GomokuRules) LegalMoves(g *Game) []Move {
+	moves := g.Board.AvailableMoves()
+	if r.Variant != GomokuRenju || !r.isRestricted(g, g.Current) {
+		return moves
+	}
+
+	legal := moves[:0]
+	for _, m := range moves {
+		if renjuForbidden(g.Board, g.Current, m.X, m.Y) == nil {
+			legal = append(legal, m)
+		}
+	}
+	return legal
+}
+
+// ValidateMove checks the target cell, then the Renju restrictions.
+// Forbidden moves are reported as ErrOverline, ErrDoubleFour or ErrDoubleThree.
+func (r GomokuRules) ValidateMove(g *Game, m Move) error {
+	if err := r.StandardRules.ValidateMove(g, m); err != nil {
+		return err
+	}
+	if r.Variant == GomokuRenju && r.isRestricted(g, g.Current) {
+		return renjuForbidden(g.Board, g.Current, m.X, m.Y)
+	}
+	return nil
+}
+
+// Outcome checks the runs through the played cell against the variant's
+// notion of a winning line, then the draw condition.
+func (r GomokuRules) Outcome(g *Game, m Move) Outcome {
+	player := g.Board.Cells[m.X][m.Y]
+	target := g.Board.effectiveToWin()
+	exact := r.Variant == GomokuStandard ||
+		(r.Variant == GomokuRenju && r.isRestricted(g, player))
+
+	for _, dir := range winDirections {
+		sx, sy, length := g.Board.runThrough(m.X, m.Y, dir, player)
+		if length == target || (!exact && length > target) {
+			win := g.Board.lineResult(sx, sy, dir, player, length)
+			return Outcome{Over: true, Winner: player, Line: win}
+		}
+	}
+
+	if g.Board.CheckDraw() {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// isRestricted returns true if player is subject to the Renju restrictions.
+func (r GomokuRules) isRestricted(g *Game, player *Player) bool {
+	return player != nil && g.FirstPlayer() == player
+}
+
+// renjuForbidden returns the Renju restriction violated by player placing
+// a mark on the empty cell (x, y), or nil if the move is allowed.
+//
+// A move making exactly five always wins and is never forbidden.
+func renjuForbidden(b *Board, player *Player, x, y int) error {
+	target := b.effectiveToWin()
+
+	b.Cells[x][y] = player
+	defer func() { b.Cells[x][y] = nil }()
+
+	overline := false
+	for _, dir := range winDirections {
+		_, _, length := b.runThrough(x, y, dir, player)
+		if length == target {
+			return nil
+		}
+		if length > target {
+			overline = true
+		}
+	}
+	if overline {
+		return ErrOverline
+	}
+
+	fours, threes := 0, 0
+	for _, dir := range winDirections {
+		if n := countFours(b, player, x, y, dir, target); n > 0 {
+			fours += n
+			continue
+		}
+		if isOpenThree(b, player, x, y, dir, target) {
+			threes++
+		}
+	}
+
+	switch {
+	case fours >= 2:
+		return ErrDoubleFour
+	case threes >= 2:
+		return ErrDoubleThree
+	default:
+		return nil
+	}
+}
+
+// countFours counts the fours through (x, y) along dir: groups of marks that
+// one more move would turn into exactly target in a row.
+//
+// The two ends of a straight four count as a single four; two completion
+// cells at any other distance (e.g. "X.XXX.X") count as two.
+func countFours(b *Board, player *Player, x, y int, dir Direction, target int) int {
+	var completions []int
+
+	for k := -(target - 1); k <= target-1; k++ {
+		cx, cy, ok := b.wrap(x+dir.DX*k, y+dir.DY*k)
+		if k == 0 || !ok || b.Cells[cx][cy] != nil {
+			continue
+		}
+
+		b.Cells[cx][cy] = player
+		_, _, length := b.runThrough(x, y, dir, player)
+		b.Cells[cx][cy] = nil
+
+		if length == target {
+			completions = append(completions, k)
+		}
+	}
+
+	switch {
+	case len(completions) == 0:
+		return 0
+	case len(completions) == 2 && completions[1]-completions[0] == straightFourGap:
+		return 1
+	case len(completions) >= maxFoursPerLine:
+		return maxFoursPerLine
+	default:
+		return 1
+	}
+}
+
+// isOpenThree reports whether one more move along dir can turn the marks
+// through (x, y) into a straight four.
+func isOpenThree(b *Board, player *Player, x, y int, dir Direction, target int) bool {
+	for k := -(target - 1); k <= target-1; k++ {
+		cx, cy, ok := b.wrap(x+dir.DX*k, y+dir.DY*k)
+		if k == 0 || !ok || b.Cells[cx][cy] != nil {
+			continue
+		}
+
+		b.Cells[cx][cy] = player
+		straight := isStraightFour(b, player, x, y, dir, target)
+		b.Cells[cx][cy] = nil
+
+		if straight {
+			return true
+		}
+	}
+	return false
+}
+
+// isStraightFour reports whether the run through (x, y) along dir is a
+// straight four: target-1 marks with both ends empty, each end completing
+// exactly target in a row.
+func isStraightFour(b *Board, player *Player, x, y int, dir Direction, target int) bool {
+	sx, sy, length := b.runThrough(x, y, dir, player)
+	if length != target-1 {
+		return false
+	}
+
+	before := Move{X: sx - dir.DX, Y: sy - dir.DY}
+	after := Move{X: sx + dir.DX*length, Y: sy + dir.DY*length}
+	for _, end := range []Move{before, after} {
+		ex, ey, ok := b.wrap(end.X, end.Y)
+		if !ok || b.Cells[ex][ey] != nil {
+			return false
+		}
+	}
+
+	// Completing on one end must not touch another mark beyond it (overline).
+	beyondBefore := Move{X: before.X - dir.DX, Y: before.Y - dir.DY}
+	beyondAfter := Move{X: after.X + dir.DX, Y: after.Y + dir.DY}
+	for _, cell := range []Move{beyondBefore, beyondAfter} {
+		if cx, cy, ok := b.wrap(cell.X, cell.Y); ok && b.Cells[cx][cy] == player {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+// newRenjuGame returns a Renju game on a standard board, with the marks of
+// the first (restricted) player at black and of the second at white.
+func newRenjuGame(black, white []Move) *Game {
+	g := newTestGame(GomokuBoardSize, GomokuBoardSize, GomokuToWin, 2)
+	g.Rules = GomokuRules{Variant: GomokuRenju}
+	for _, m := range black {
+		g.Board.Cells[m.X][m.Y] = g.Players[0]
+	}
+	for _, m := range white {
+		g.Board.Cells[m.X][m.Y] = g.Players[1]
+	}
+	return g
+}
+
+func TestRenjuRestrictions(t *testing.T) {
+	tests := []struct {
+		name   string
+		black  []Move
+		white  []Move
+		move   Move
+		second bool  // The second player plays the move
+		want   error // nil = allowed
+	}{
+		{
+			name:  "double three",
+			black: []Move{{X: 7, Y: 5}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}},
+			move:  NewMove(7, 7),
+			want:  ErrDoubleThree,
+		},
+		{
+			name:  "double three with a split three",
+			black: []Move{{X: 7, Y: 4}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}},
+			move:  NewMove(7, 7),
+			want:  ErrDoubleThree,
+		},
+		{
+			name:  "three closed on one side",
+			black: []Move{{X: 7, Y: 5}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}},
+			white: []Move{{X: 7, Y: 4}},
+			move:  NewMove(7, 7),
+		},
+		{
+			name:  "double four",
+			black: []Move{{X: 7, Y: 4}, {X: 7, Y: 5}, {X: 7, Y: 6}, {X: 4, Y: 7}, {X: 5, Y: 7}, {X: 6, Y: 7}},
+			move:  NewMove(7, 7),
+			want:  ErrDoubleFour,
+		},
+		{
+			name:  "double four on one line",
+			black: []Move{{X: 3, Y: 7}, {X: 5, Y: 7}, {X: 6, Y: 7}, {X: 9, Y: 7}},
+			move:  NewMove(7, 7),
+			want:  ErrDoubleFour,
+		},
+		{
+			name:  "four three",
+			black: []Move{{X: 7, Y: 4}, {X: 7, Y: 5}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}},
+			move:  NewMove(7, 7),
+		},
+		{
+			name:  "overline",
+			black: []Move{{X: 2, Y: 7}, {X: 3, Y: 7}, {X: 4, Y: 7}, {X: 6, Y: 7}, {X: 7, Y: 7}},
+			move:  NewMove(5, 7),
+			want:  ErrOverline,
+		},
+		{
+			name:  "five beside a double three",
+			black: []Move{{X: 3, Y: 7}, {X: 4, Y: 7}, {X: 5, Y: 7}, {X: 6, Y: 7}, {X: 7, Y: 5}, {X: 7, Y: 6}, {X: 8, Y: 8}, {X: 9, Y: 9}},
+			move:  NewMove(7, 7),
+		},
+		{
+			name:   "second player is not restricted",
+			white:  []Move{{X: 7, Y: 5}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}},
+			black:  []Move{{X: 0, Y: 0}},
+			move:   NewMove(7, 7),
+			second: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newRenjuGame(tt.black, tt.white)
+			if tt.second {
+				g.Current = g.Players[1]
+			}
+
+			if err := g.ValidateMove(tt.move); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateMove() = %v, want %v", err, tt.want)
+			}
+			legal := false
+			for _, m := range g.LegalMoves() {
+				legal = legal || m == tt.move
+			}
+			if legal != (tt.want == nil) {
+				t.Errorf("move in LegalMoves() = %v, want %v", legal, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestRenjuRestrictsTheFirstPlayer(t *testing.T) {
+	// Double three of the second player, who moves first
+	g := newRenjuGame(nil, []Move{{X: 7, Y: 5}, {X: 7, Y: 6}, {X: 5, Y: 7}, {X: 6, Y: 7}})
+	g.SetFirstPlayer(g.Players[1])
+	if err := g.ValidateMove(NewMove(7, 7)); !errors.Is(err, ErrDoubleThree) {
+		t.Errorf("ValidateMove() = %v, want %v", err, ErrDoubleThree)
+	}
+}
+
+func TestRenjuOverlineWinsForTheSecondPlayer(t *testing.T) {
+	white := []Move{{X: 2, Y: 7}, {X: 3, Y: 7}, {X: 4, Y: 7}, {X: 6, Y: 7}, {X: 7, Y: 7}}
+	g := newRenjuGame([]Move{{X: 0, Y: 0}}, white)
+	g.Current = g.Players[1]
+	mustPlay(t, g, NewMove(5, 7))
+	if g.Winner != g.Players[1] {
+		t.Errorf("overline of the second player did not win")
+	}
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"image/color"
+	"testing"
+)
+
+// newTestPlayers returns n players without symbols, which tests do not
+// need.
+func newTestPlayers(n int) []*Player {
+	players := make([]*Player, n)
+	for i := range players {
+		players[i] = NewPlayer(nil, color.Black)
+	}
+	return players
+}
+
+// newTestGame returns a game of n players on a width x height board.
+func newTestGame(width, height, toWin, n int) *Game {
+	return NewGameWithConfig(width, height, toWin, newTestPlayers(n))
+}
+
+// mustPlay plays the given moves, failing the test on the first refused
+// one.
+func mustPlay(t *testing.T, g *Game, moves ...Move) {
+	t.Helper()
+	for _, m := range moves {
+		if err := g.Play(m); err != nil {
+			t.Fatalf("playing %v: %v", m, err)
+		}
+	}
+}
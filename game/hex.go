@@ -0,0 +1,186 @@
+package game
+
+// Hex configuration: a hexagon of 37 cells, four in a row wins.
+const (
+	HexRadius = 3 // Number of rings around the center cell
+	HexToWin  = 4 // Four in a row
+)
+
+// hexDirections contains the three axes of a hex grid, in axial
+// coordinates (DX is the q step, DY the r step). As with winDirections,
+// the opposite directions are covered by scanning both ways.
+var hexDirections = [...]Direction{
+	{DX: 1, DY: 0},  // along q (→)
+	{DX: 0, DY: 1},  // along r (↘)
+	{DX: 1, DY: -1}, // along s (↗)
+}
+
+// HexCell is a cell of a HexBoard in axial coordinates, relative to the
+// center cell: Q grows to the right and R down to the right.
+type HexCell struct {
+	Q int // Axial column
+	R int // Axial row
+}
+
+// HexBoard is a hexagon-shaped hex grid view of a flat Board.
+//
+// Cell (q, r) is stored in the flat cell (q+Radius, r+Radius) of a square
+// board of 2*Radius+1 cells per side. Steps between neighbors are the same
+// on both grids, so the hex lines are lines of the flat board along
+// hexDirections. The flat cells outside the hexagon are void (see HexMask),
+// which keeps moves, history, undo and records working on the flat board.
+type HexBoard struct {
+	Board  *Board // Flat storage of the hexagon
+	Radius int    // Number of rings around the center cell
+}
+
+// NewHexBoard creates an empty hexagon of the given radius.
+func NewHexBoard(radius, toWin int) *HexBoard {
+	b := NewBoard(2*radius+1, 2*radius+1, toWin)
+	b.SetMask(HexMask(radius))
+	return HexBoardOf(b)
+}
+
+// HexBoardOf returns the hex view of a flat board of 2*radius+1 cells per side.
+func HexBoardOf(b *Board) *HexBoard {
+	return &HexBoard{Board: b, Radius: (b.Width - 1) / 2}
+}
+
+// HexMask returns the mask of the flat board of a hexagon of the given
+// radius, leaving the cells outside the hexagon void.
+func HexMask(radius int) [][]CellMask {
+	h := &HexBoard{Radius: radius}
+	size := 2*radius + 1
+
+	mask := make([][]CellMask, size)
+	for x := range mask {
+		mask[x] = make([]CellMask, size)
+		for y := range mask[x] {
+			if !h.InBounds(h.Unflatten(x, y)) {
+				mask[x][y] = CellVoid
+			}
+		}
+	}
+	return mask
+}
+
+// Flatten returns the flat board coordinates of cell c.
+func (h *HexBoard) Flatten(c HexCell) (int, int) {
+	return c.Q + h.Radius, c.R + h.Radius
+}
+
+// Unflatten returns the hex cell at flat board coordinates (x, y).
+func (h *HexBoard) Unflatten(x, y int) HexCell {
+	return HexCell{Q: x - h.Radius, R: y - h.Radius}
+}
+
+// InBounds reports whether c lies within the hexagon.
+func (h *HexBoard) InBounds(c HexCell) bool {
+	s := -c.Q - c.R
+	return abs(c.Q) <= h.Radius && abs(c.R) <= h.Radius && abs(s) <= h.Radius
+}
+
+// Cells returns every cell of the hexagon, row by row.
+func (h *HexBoard) Cells() []HexCell {
+	var cells []HexCell
+	for r := -h.Radius; r <= h.Radius; r++ {
+		for q := -h.Radius; q <= h.Radius; q++ {
+			if c := (HexCell{Q: q, R: r}); h.InBounds(c) {
+				cells = append(cells, c)
+			}
+		}
+	}
+	return cells
+}
+
+// At returns the player owning cell c, or nil.
+func (h *HexBoard) At(c HexCell) *Player {
+	if !h.InBounds(c) {
+		return nil
+	}
+	x, y := h.Flatten(c)
+	return h.Board.Cells[x][y]
+}
+
+// CheckWinAt looks for a winning alignment passing through cell c along
+// the three hex axes. The cells of the result are flat board cells.
+func (h *HexBoard) CheckWinAt(c HexCell) WinResult {
+	player := h.At(c)
+	if player == nil {
+		return WinResult{}
+	}
+	x, y := h.Flatten(c)
+	return h.Board.lineAlong(x, y, player, hexDirections[:])
+}
+
+// Lines returns every possible winning line, as flat board cells.
+func (h *HexBoard) Lines() [][]Move {
+	target := h.Board.effectiveToWin()
+
+	var lines [][]Move
+	for _, c := range h.Cells() {
+		for _, dir := range hexDirections {
+			end := HexCell{Q: c.Q + dir.DX*(target-1), R: c.R + dir.DY*(target-1)}
+			if !h.InBounds(end) {
+				continue
+			}
+			x, y := h.Flatten(c)
+			lines = append(lines, h.Board.lineResult(x, y, dir, nil, target).Cells)
+		}
+	}
+	return lines
+}
+
+// HexRules implements k-in-a-row on a HexBoard stored in the game's flat
+// board, whose cells outside the hexagon are expected to be void (see
+// HexMask). Lines run along the three axes of the grid. Gravity is not
+// supported.
+type HexRules struct {
+	StandardRules
+}
+
+// HexBoard returns the hex view of the game board.
+func (HexRules) HexBoard(g *Game) *HexBoard {
+	return HexBoardOf(g.Board)
+}
+
+// LegalMoves returns every empty cell of the hexagon, in flat board coordinates.
+func (r HexRules) LegalMoves(g *Game) []Move {
+	h := r.HexBoard(g)
+
+	var moves []Move
+	for _, c := range h.Cells() {
+		if h.At(c) == nil {
+			x, y := h.Flatten(c)
+			moves = append(moves, Move{X: x, Y: y})
+		}
+	}
+	return moves
+}
+
+// ValidateMove checks that m targets an empty cell of the hexagon.
+func (r HexRules) ValidateMove(g *Game, m Move) error {
+	h := r.HexBoard(g)
+	if !g.Board.inBounds(m.X, m.Y) || !h.InBounds(h.Unflatten(m.X, m.Y)) {
+		return ErrOutOfBounds
+	}
+	return r.StandardRules.ValidateMove(g, m)
+}
+
+// Outcome checks the three lines through the played cell, then whether
+// the hexagon is full.
+func (r HexRules) Outcome(g *Game, m Move) Outcome {
+	h := r.HexBoard(g)
+	if win := h.CheckWinAt(h.Unflatten(m.X, m.Y)); win.Winner != nil {
+		return Outcome{Over: true, Winner: win.Winner, Line: win}
+	}
+	if len(r.LegalMoves(g)) == 0 {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// WinningLines returns every line of the hexagon, in flat board coordinates.
+func (r HexRules) WinningLines(g *Game) [][]Move {
+	return r.HexBoard(g).Lines()
+}
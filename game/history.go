@@ -0,0 +1,187 @@
+package game
+
+// MoveRecord describes a single move stored in the game history.
+type MoveRecord struct {
+	Player *Player // Player who made the move
+	X      int     // Column of the placed mark
+	Y      int     // Row of the placed mark
+	Turn   int     // Turn number of the move (1-based)
+
+	FromX     int  // Column the mark slid from (slides only)
+	FromY     int  // Row the mark slid from (slides only)
+	HasSource bool // True if the move slid a mark instead of placing one
+
+	PairX   int  // Column of the second cell of a spooky move
+	PairY   int  // Row of the second cell of a spooky move
+	HasPair bool // True if the move placed two entangled marks
+
+	Mark *Player // Player whose symbol was placed, nil for the mover's own
+}
+
+// Move returns the move described by the record.
+func (r MoveRecord) Move() Move {
+	return Move{
+		X: r.X, Y: r.Y,
+		FromX: r.FromX, FromY: r.FromY, HasSource: r.HasSource,
+		PairX: r.PairX, PairY: r.PairY, HasPair: r.HasPair,
+		Mark: r.Mark,
+	}
+}
+
+// gameSnapshot captures the game fields that a move may change,
+// apart from the board cells which are tracked as cellChange entries.
+type gameSnapshot struct {
+	current  *Player
+	winner   *Player
+	loser    *Player
+	timedOut *Player
+	winLine  WinResult
+	state    GameState
+	turn     int
+	placed   int
+	points   []int // Points of each player, indexed like Game.Players
+	captures []int // Captures of each player, indexed like Game.Players
+	lines    []int // Lines of each player, indexed like Game.Players
+}
+
+// historyEntry stores everything needed to undo and redo one move.
+type historyEntry struct {
+	record  MoveRecord
+	changes []cellChange
+	quantum *QuantumState // State after the move, cached by QuantumRules.State (nil = not computed)
+	before  gameSnapshot
+	after   gameSnapshot
+}
+
+// History returns the moves played so far, oldest first.
+//
+// Moves that were undone (and can still be redone) are not included.
+// The returned slice is a copy and can be modified freely.
+func (g *Game) History() []MoveRecord {
+	records := make([]MoveRecord, g.cursor)
+	for i := 0; i < g.cursor; i++ {
+		records[i] = g.history[i].record
+	}
+	return records
+}
+
+// LastMove returns the most recent move still applied.
+// The boolean is false if no move has been played (or all were undone).
+func (g *Game) LastMove() (MoveRecord, bool) {
+	if g.cursor == 0 {
+		return MoveRecord{}, false
+	}
+	return g.history[g.cursor-1].record, true
+}
+
+// CanUndo returns true if at least one move can be undone.
+func (g *Game) CanUndo() bool {
+	return g.cursor > 0
+}
+
+// CanRedo returns true if at least one undone move can be replayed.
+func (g *Game) CanRedo() bool {
+	return g.cursor < len(g.history)
+}
+
+// Undo takes back the last move.
+//
+// Board cells, the current player, the winner, the game state and
+// the points awarded by the move are restored to their previous values.
+// Returns false if there is no move to undo.
+func (g *Game) Undo() bool {
+	if !g.CanUndo() {
+		return false
+	}
+
+	g.cursor--
+	entry := &g.history[g.cursor]
+
+	// Revert cell changes in reverse order so overlapping writes unwind correctly.
+	store := g.Store()
+	for i := len(entry.changes) - 1; i >= 0; i-- {
+		c := entry.changes[i]
+		store.set(c.X, c.Y, c.Before)
+	}
+	g.restoreSnapshot(entry.before)
+	return true
+}
+
+// Redo replays the last undone move.
+// Returns false if there is no move to redo.
+func (g *Game) Redo() bool {
+	if !g.CanRedo() {
+		return false
+	}
+
+	entry := &g.history[g.cursor]
+	g.cursor++
+
+	store := g.Store()
+	for _, c := range entry.changes {
+		store.set(c.X, c.Y, c.After)
+	}
+	g.restoreSnapshot(entry.after)
+	return true
+}
+
+// clearHistory forgets every recorded move.
+func (g *Game) clearHistory() {
+	g.history = nil
+	g.cursor = 0
+}
+
+// pushHistory appends a move to the history, discarding any redoable moves.
+func (g *Game) pushHistory(entry historyEntry) {
+	g.history = append(g.history[:g.cursor], entry)
+	g.cursor++
+}
+
+// takeSnapshot captures the current game fields.
+func (g *Game) takeSnapshot() gameSnapshot {
+	points := make([]int, len(g.Players))
+	captures := make([]int, len(g.Players))
+	lines := make([]int, len(g.Players))
+	for i, player := range g.Players {
+		points[i] = player.Points
+		captures[i] = player.Captures
+		lines[i] = player.Lines
+	}
+
+	return gameSnapshot{
+		current:  g.Current,
+		winner:   g.Winner,
+		loser:    g.Loser,
+		timedOut: g.timedOut,
+		winLine:  g.WinLine,
+		state:    g.State,
+		turn:     g.turn,
+		placed:   g.placed,
+		points:   points,
+		captures: captures,
+		lines:    lines,
+	}
+}
+
+// restoreSnapshot applies a previously captured snapshot to the game.
+func (g *Game) restoreSnapshot(s gameSnapshot) {
+	g.Current = s.current
+	g.Winner = s.winner
+	g.Loser = s.loser
+	g.timedOut = s.timedOut
+	g.WinLine = s.winLine
+	g.State = s.state
+	g.turn = s.turn
+	g.placed = s.placed
+	for i, player := range g.Players {
+		if i < len(s.points) {
+			player.Points = s.points[i]
+		}
+		if i < len(s.captures) {
+			player.Captures = s.captures[i]
+		}
+		if i < len(s.lines) {
+			player.Lines = s.lines[i]
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package game
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// gameView is what a player sees of a game, players given by their index
+// (-1 = none).
+type gameView struct {
+	Cells    [][]int
+	Current  int
+	Winner   int
+	Loser    int
+	State    GameState
+	Turn     int
+	Placed   int
+	Points   []int
+	Captures []int
+	Lines    []int
+	History  int
+}
+
+func viewOf(g *Game) gameView {
+	v := gameView{
+		Current: slices.Index(g.Players, g.Current),
+		Winner:  slices.Index(g.Players, g.Winner),
+		Loser:   slices.Index(g.Players, g.Loser),
+		State:   g.State,
+		Turn:    g.Turn(),
+		Placed:  g.placed,
+		History: len(g.History()),
+	}
+	for _, column := range g.Board.Cells {
+		cells := make([]int, len(column))
+		for y, owner := range column {
+			cells[y] = slices.Index(g.Players, owner)
+		}
+		v.Cells = append(v.Cells, cells)
+	}
+	for _, p := range g.Players {
+		v.Points = append(v.Points, p.Points)
+		v.Captures = append(v.Captures, p.Captures)
+		v.Lines = append(v.Lines, p.Lines)
+	}
+	return v
+}
+
+func TestUndoRedoWholeHistory(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func() *Game
+		moves []Move
+		check func(t *testing.T, g *Game) // Checks the scenario did what it is about
+	}{
+		{
+			name:  "win",
+			setup: func() *Game { return newTestGame(3, 3, 3, 2) },
+			moves: []Move{NewMove(0, 0), NewMove(0, 1), NewMove(1, 0), NewMove(1, 1), NewMove(2, 0)},
+			check: func(t *testing.T, g *Game) {
+				if g.Winner != g.Players[0] || g.Players[0].Points != 1 {
+					t.Errorf("first player did not win the round")
+				}
+			},
+		},
+		{
+			name: "Connect6 schedule",
+			setup: func() *Game {
+				g := newTestGame(7, 7, 6, 2)
+				g.Schedule = Connect6Schedule
+				return g
+			},
+			moves: []Move{
+				NewMove(3, 3),
+				NewMove(0, 0), NewMove(0, 1),
+				NewMove(3, 4), NewMove(3, 5),
+				NewMove(1, 0),
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Current != g.Players[1] || g.PlacementsLeft() != 1 {
+					t.Errorf("second player is not in the middle of their turn")
+				}
+			},
+		},
+		{
+			name: "mark limit",
+			setup: func() *Game {
+				g := newTestGame(4, 4, 4, 2)
+				g.MaxMarks = 2
+				return g
+			},
+			moves: []Move{
+				NewMove(0, 0), NewMove(3, 3), NewMove(1, 0), NewMove(2, 3),
+				NewMove(2, 0), NewMove(1, 3), NewMove(0, 1),
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Board.Cells[0][0] != nil || g.Board.Cells[1][0] != nil || g.Board.Cells[3][3] != nil {
+					t.Errorf("oldest marks were not removed")
+				}
+			},
+		},
+		{
+			name: "Pente capture",
+			setup: func() *Game {
+				g := newTestGame(7, 7, PenteToWin, 2)
+				g.Rules = PenteRules{}
+				return g
+			},
+			moves: []Move{
+				NewMove(0, 0), NewMove(1, 0), NewMove(6, 6), NewMove(2, 0), NewMove(3, 0),
+				NewMove(4, 4), NewMove(1, 0),
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Players[0].Captures != 1 || g.Board.Cells[2][0] != nil {
+					t.Errorf("pair was not captured")
+				}
+			},
+		},
+		{
+			name: "slides",
+			setup: func() *Game {
+				g := newTestGame(3, 3, 3, 2)
+				g.Rules = SlidingRules{Placements: 3}
+				return g
+			},
+			moves: []Move{
+				NewMove(0, 0), NewMove(2, 2), NewMove(2, 0), NewMove(1, 0), NewMove(0, 2), NewMove(0, 1),
+				NewSlide(2, 0, 2, 1), NewSlide(1, 0, 1, 1), NewSlide(0, 0, 1, 0),
+			},
+			check: func(t *testing.T, g *Game) {
+				if g.Board.Cells[1][0] != g.Players[0] || g.Board.Cells[0][0] != nil {
+					t.Errorf("mark did not slide")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.setup()
+			start := viewOf(g)
+			mustPlay(t, g, tt.moves...)
+			tt.check(t, g)
+			end := viewOf(g)
+
+			for g.Undo() {
+			}
+			if got := viewOf(g); !reflect.DeepEqual(got, start) {
+				t.Errorf("after undoing every move:\n got %+v\nwant %+v", got, start)
+			}
+
+			for g.Redo() {
+			}
+			if got := viewOf(g); !reflect.DeepEqual(got, end) {
+				t.Errorf("after redoing every move:\n got %+v\nwant %+v", got, end)
+			}
+		})
+	}
+}
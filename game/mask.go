@@ -0,0 +1,153 @@
+package game
+
+import "math/rand"
+
+// CellMask tells whether a cell of a Board can be played.
+type CellMask uint8
+
+// Cell mask values. Blocked and void cells are never playable and break
+// the lines going through them; they only differ in how they are drawn.
+const (
+	CellOpen    CellMask = iota // Playable cell
+	CellBlocked                 // Obstacle inside the board
+	CellVoid                    // Outside of the board shape
+)
+
+// MaskShape is a preset board shape, given by the cells it leaves void.
+type MaskShape int
+
+// Available board shapes.
+const (
+	ShapeFull    MaskShape = iota // Every cell is open
+	ShapePlus                     // The corners are void, leaving a plus sign
+	ShapeDiamond                  // Only the cells close to the center are open
+	ShapeHoled                    // The center cell (2x2 cells on even sizes) is void
+
+	MaskShapeCount // Number of board shapes
+)
+
+// Mask returns the mask of a width x height board of the shape, or nil
+// for ShapeFull.
+func (s MaskShape) Mask(width, height int) [][]CellMask {
+	if s == ShapeFull || width <= 0 || height <= 0 {
+		return nil
+	}
+
+	mask := make([][]CellMask, width)
+	for x := range mask {
+		mask[x] = make([]CellMask, height)
+		for y := range mask[x] {
+			if s.isVoid(x, y, width, height) {
+				mask[x][y] = CellVoid
+			}
+		}
+	}
+	return mask
+}
+
+// isVoid reports whether the shape leaves cell (x, y) of a width x height
+// board void.
+func (s MaskShape) isVoid(x, y, width, height int) bool {
+	// Doubled distances to the center keep even sizes symmetric
+	dx := abs(2*x - (width - 1))
+	dy := abs(2*y - (height - 1))
+
+	switch s {
+	case ShapePlus:
+		// Corner rectangles are a third of the board wide and high
+		cornerX := x < width/3 || x >= width-width/3
+		cornerY := y < height/3 || y >= height-height/3
+		return cornerX && cornerY
+	case ShapeDiamond:
+		return dx*height+dy*width > width*height
+	case ShapeHoled:
+		return dx <= 1 && dy <= 1
+	default:
+		return false
+	}
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// MaskAt returns the mask of cell (x, y). Cells of a board without a mask,
+// and cells out of bounds, are reported open.
+func (b *Board) MaskAt(x, y int) CellMask {
+	if !b.inBounds(x, y) || x >= len(b.Mask) || y >= len(b.Mask[x]) {
+		return CellOpen
+	}
+	return b.Mask[x][y]
+}
+
+// SetMask replaces the mask of the board by a copy of mask (nil = every
+// cell open). Marks on cells that are no longer open are removed.
+func (b *Board) SetMask(mask [][]CellMask) {
+	b.Mask = nil
+	for x := 0; x < b.Width && x < len(mask); x++ {
+		for y := 0; y < b.Height && y < len(mask[x]); y++ {
+			b.SetCellMask(x, y, mask[x][y])
+		}
+	}
+}
+
+// SetCellMask sets the mask of cell (x, y), removing its mark if the cell
+// is no longer open. Out of bounds cells are ignored.
+func (b *Board) SetCellMask(x, y int, m CellMask) {
+	if !b.inBounds(x, y) {
+		return
+	}
+	if b.Mask == nil {
+		if m == CellOpen {
+			return
+		}
+		b.Mask = make([][]CellMask, b.Width)
+		for i := range b.Mask {
+			b.Mask[i] = make([]CellMask, b.Height)
+		}
+	}
+
+	b.Mask[x][y] = m
+	if m != CellOpen {
+		b.Cells[x][y] = nil
+	}
+}
+
+// IsMasked reports whether some cell of the board is not open.
+func (b *Board) IsMasked() bool {
+	for x := range b.Mask {
+		for y := range b.Mask[x] {
+			if b.Mask[x][y] != CellOpen {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BlockRandomCells turns up to n random open empty cells into obstacles
+// and returns the number of cells blocked.
+func (b *Board) BlockRandomCells(n int) int {
+	var free []Move
+	for x := 0; x < b.Width; x++ {
+		for y := 0; y < b.Height; y++ {
+			if b.isValidPosition(x, y) && b.Cells[x][y] == nil {
+				free = append(free, Move{X: x, Y: y})
+			}
+		}
+	}
+
+	blocked := 0
+	for _, i := range rand.Perm(len(free)) {
+		if blocked >= n {
+			break
+		}
+		b.SetCellMask(free[i].X, free[i].Y, CellBlocked)
+		blocked++
+	}
+	return blocked
+}
@@ -0,0 +1,209 @@
+package game
+
+import "slices"
+
+// MatchFormat decides when a match ends.
+type MatchFormat int
+
+const (
+	// MatchOpen never ends: points accumulate from round to round.
+	MatchOpen MatchFormat = iota
+	// MatchBestOf ends after Match.Target rounds, or as soon as a side
+	// leads by more points than the remaining rounds can make up.
+	MatchBestOf
+	// MatchFirstTo ends when a side alone reaches Match.Target points.
+	MatchFirstTo
+)
+
+// StartRotation decides who moves first in the rounds after the first one.
+type StartRotation int
+
+const (
+	// StartFixed gives the first move of every round to the same player.
+	StartFixed StartRotation = iota
+	// StartAlternate passes the first move to the next player each round.
+	StartAlternate
+	// StartLoser gives the first move to the loser of the previous round.
+	StartLoser
+	// StartWinner gives the first move to the winner of the previous round.
+	StartWinner
+
+	StartRotationCount // Number of starting player rotations
+)
+
+// Match plays a series of rounds of a game and decides when it is over.
+//
+// Points are the ones scored by the rules (see Ruleset.Score), teammates
+// sharing theirs, and are counted in wins: a quantum tic-tac-toe win, worth
+// QuantumWinPoints, counts as one point. A side is a team, or a player
+// without one.
+//
+// The match follows the game: a round ends when the game does, and undoing
+// the last move of a round takes the result back.
+type Match struct {
+	Game     *Game         // Game the rounds are played on
+	Format   MatchFormat   // When the match ends
+	Target   int           // Rounds of a best-of match, points of a first-to match
+	Rotation StartRotation // Who moves first in the next rounds
+
+	first  *Player // Player moving first in the first round
+	rounds int     // Rounds finished before the current one
+}
+
+// NewMatch starts a match on g, whose current first player starts the
+// first round.
+func NewMatch(g *Game, format MatchFormat, target int, rotation StartRotation) *Match {
+	return &Match{
+		Game:     g,
+		Format:   format,
+		Target:   target,
+		Rotation: rotation,
+		first:    g.FirstPlayer(),
+	}
+}
+
+// Round returns the number of the current round (1-based).
+func (m *Match) Round() int {
+	return m.rounds + 1
+}
+
+// Played returns the number of finished rounds, the current one included
+// once it has ended.
+func (m *Match) Played() int {
+	if m.Game.IsGameEnd() {
+		return m.rounds + 1
+	}
+	return m.rounds
+}
+
+// Sides returns the first player of each side, in turn order.
+func (m *Match) Sides() []*Player {
+	var sides []*Player
+	for _, p := range m.Game.Players {
+		if !slices.ContainsFunc(sides, p.SameSide) {
+			sides = append(sides, p)
+		}
+	}
+	return sides
+}
+
+// Points returns the points of the side of p, in wins. Half points of
+// quantum tic-tac-toe are rounded down.
+func (m *Match) Points(p *Player) int {
+	return m.Game.TeamPoints(p) / m.winPoints()
+}
+
+// winPoints returns the points scored by the rules for a round win.
+func (m *Match) winPoints() int {
+	if _, ok := m.Game.Rules.(QuantumRules); ok {
+		return QuantumWinPoints
+	}
+	return 1
+}
+
+// Over reports whether the match has ended. It never does while a round
+// is being played.
+func (m *Match) Over() bool {
+	over, _ := m.result()
+	return over
+}
+
+// Winner returns the first player of the side that won the match, nil
+// while it goes on or if it ended in a draw.
+func (m *Match) Winner() *Player {
+	_, winner := m.result()
+	return winner
+}
+
+// result decides whether the match is over and who won it.
+func (m *Match) result() (bool, *Player) {
+	if m.Format == MatchOpen || !m.Game.IsGameEnd() {
+		return false, nil
+	}
+
+	// Compare the best side with the runner-up, in game points so that
+	// half points still count
+	sides := m.Sides()
+	var leader *Player
+	lead, second := 0, 0
+	for i, p := range sides {
+		switch points := m.Game.TeamPoints(p); {
+		case i == 0 || points > lead:
+			leader, lead, second = p, points, lead
+		case points > second:
+			second = points
+		}
+	}
+	if len(sides) > 1 && lead == second {
+		leader = nil
+	}
+
+	target := m.Target * m.winPoints()
+	switch m.Format {
+	case MatchBestOf:
+		// A side may win every remaining round, scoring winPoints in each
+		left := (m.Target - m.Played()) * m.winPoints()
+		if left <= 0 || lead-second > left {
+			return true, leader
+		}
+	case MatchFirstTo:
+		if leader != nil && lead >= target {
+			return true, leader
+		}
+	}
+	return false, nil
+}
+
+// NextRound starts the next round, choosing its first player by the
+// rotation. It returns false, doing nothing, while the current round is
+// being played or once the match is over.
+func (m *Match) NextRound() bool {
+	if !m.Game.IsGameEnd() || m.Over() {
+		return false
+	}
+
+	m.Game.SetFirstPlayer(m.nextFirst())
+	m.rounds++
+	m.Game.Reset()
+	return true
+}
+
+// Restart clears the points and starts a new match, whose first round is
+// started by the first player of the previous match.
+func (m *Match) Restart() {
+	m.rounds = 0
+	m.Game.SetFirstPlayer(m.first)
+	m.Game.Reset()
+	m.Game.ResetPoints()
+}
+
+// nextFirst returns the player moving first in the next round. Without a
+// loser or a winner to give the first move to, it passes to the next
+// player.
+func (m *Match) nextFirst() *Player {
+	g := m.Game
+	current := g.FirstPlayer()
+	alternate := nextInOrder(g.Players, current)
+
+	switch m.Rotation {
+	case StartFixed:
+		return current
+	case StartLoser:
+		if g.Loser != nil {
+			return g.Loser
+		}
+		if g.Winner != nil {
+			// The first opponent of the winner after them in turn order
+			for p := nextInOrder(g.Players, g.Winner); p != g.Winner; p = nextInOrder(g.Players, p) {
+				if !g.Winner.SameSide(p) {
+					return p
+				}
+			}
+		}
+	case StartWinner:
+		if g.Winner != nil {
+			return g.Winner
+		}
+	}
+	return alternate
+}
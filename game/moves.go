@@ -5,9 +5,30 @@ package game
 // Move represents a single move on the game board as grid coordinates.
 // X is the column index (0-based, left to right).
 // Y is the row index (0-based, top to bottom).
+//
+// A move places a new mark on (X, Y), or, when HasSource is set, slides an
+// existing mark from (FromX, FromY) to (X, Y) during a movement phase
+// (see SlidingRules).
+//
+// A spooky move of quantum tic-tac-toe, with HasPair set, places two
+// entangled marks on (X, Y) and (PairX, PairY) (see QuantumRules).
+//
+// Mark selects the symbol placed, identified by the player it belongs to,
+// for rulesets where players may place another symbol than their own
+// (see SymbolRules). A nil Mark places the current player's own symbol.
 type Move struct {
 	X int
 	Y int
+
+	FromX     int  // Column of the source cell of a slide
+	FromY     int  // Row of the source cell of a slide
+	HasSource bool // True if the move slides the mark from (FromX, FromY)
+
+	PairX   int  // Column of the second cell of a spooky move
+	PairY   int  // Row of the second cell of a spooky move
+	HasPair bool // True if the move also marks (PairX, PairY)
+
+	Mark *Player // Player whose symbol is placed (nil = the current player's)
 }
 
 // NewMove creates a Move at the specified grid coordinates.
@@ -15,6 +36,55 @@ func NewMove(x, y int) Move {
 	return Move{X: x, Y: y}
 }
 
+// NewSlide creates a Move sliding the mark on (fromX, fromY) to (x, y).
+func NewSlide(fromX, fromY, x, y int) Move {
+	return Move{X: x, Y: y, FromX: fromX, FromY: fromY, HasSource: true}
+}
+
+// NewSpookyMove creates a Move placing two entangled marks on (x, y) and
+// (pairX, pairY).
+func NewSpookyMove(x, y, pairX, pairY int) Move {
+	return Move{X: x, Y: y, PairX: pairX, PairY: pairY, HasPair: true}
+}
+
+// WithMark returns a copy of the move placing the symbol of mark.
+func (m Move) WithMark(mark *Player) Move {
+	m.Mark = mark
+	return m
+}
+
+// MarkOf returns the player whose symbol the move places when played
+// by current.
+func (m Move) MarkOf(current *Player) *Player {
+	if m.Mark == nil {
+		return current
+	}
+	return m.Mark
+}
+
+// Cell returns the target cell of the move, without its source.
+func (m Move) Cell() Move {
+	return Move{X: m.X, Y: m.Y}
+}
+
+// Source returns the source cell of a slide.
+// The boolean is false if the move is a placement.
+func (m Move) Source() (Move, bool) {
+	if !m.HasSource {
+		return Move{}, false
+	}
+	return Move{X: m.FromX, Y: m.FromY}, true
+}
+
+// Pair returns the second cell of a spooky move.
+// The boolean is false if the move marks a single cell.
+func (m Move) Pair() (Move, bool) {
+	if !m.HasPair {
+		return Move{}, false
+	}
+	return Move{X: m.PairX, Y: m.PairY}, true
+}
+
 // IsValid checks if the move coordinates are within the given board dimensions.
 func (m Move) IsValid(boardWidth, boardHeight int) bool {
 	return m.X >= 0 && m.X < boardWidth && m.Y >= 0 && m.Y < boardHeight
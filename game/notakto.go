@@ -0,0 +1,109 @@
+package game
+
+import "errors"
+
+// Notakto configuration.
+const (
+	NotaktoBoardSize     = 3 // Width and height of each board, three in a row kills it
+	DefaultNotaktoBoards = 3 // Number of boards of a default Notakto game
+)
+
+// ErrBoardDead is returned when a Notakto move targets a board that
+// already contains three in a row.
+var ErrBoardDead = errors.New("game: board is already dead")
+
+// NotaktoRules implements Notakto on one or more NotaktoBoardSize boards
+// laid side by side in the game's flat board, like the layers of a Board3D.
+// The number of boards is derived from the board width.
+//
+// Every player places the same symbol: lines are found whoever placed their
+// marks (see Board.CheckLineAt). A board is dead once it contains a line,
+// and no more marks may be placed on it. The player who kills the last
+// board loses the round, and every other player is rewarded. With the
+// Misere option, killing the last board wins the round instead, whatever
+// the number of players.
+type NotaktoRules struct {
+	StandardRules
+}
+
+// Boards returns the boards of the game, as the layers of a Board3D.
+func (NotaktoRules) Boards(g *Game) *Board3D {
+	return Board3DOf(g.Board, max(1, g.Board.Width/NotaktoBoardSize))
+}
+
+// DeadLines returns the line that killed each dead board, in flat board
+// coordinates.
+func (r NotaktoRules) DeadLines(g *Game) []WinResult {
+	boards := r.Boards(g)
+
+	var lines []WinResult
+	for z := 0; z < boards.Depth; z++ {
+		if line := boards.Layer(z).FindLine(); line.Winner != nil {
+			lines = append(lines, flatLayerLine(boards, z, line))
+		}
+	}
+	return lines
+}
+
+// IsDead reports whether board z contains a line.
+func (r NotaktoRules) IsDead(g *Game, z int) bool {
+	return r.Boards(g).Layer(z).FindLine().Winner != nil
+}
+
+// LegalMoves returns the empty cells of the boards still alive.
+func (r NotaktoRules) LegalMoves(g *Game) []Move {
+	boards := r.Boards(g)
+	dead := make([]bool, boards.Depth)
+	for z := range dead {
+		dead[z] = r.IsDead(g, z)
+	}
+
+	var moves []Move
+	for _, m := range r.StandardRules.LegalMoves(g) {
+		if !dead[boards.Unflatten(m.X, m.Y).Z] {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+// ValidateMove checks that m targets an empty cell of a board still alive.
+func (r NotaktoRules) ValidateMove(g *Game, m Move) error {
+	if err := r.StandardRules.ValidateMove(g, m); err != nil {
+		return err
+	}
+	if r.IsDead(g, r.Boards(g).Unflatten(m.X, m.Y).Z) {
+		return ErrBoardDead
+	}
+	return nil
+}
+
+// Outcome ends the round once no board is alive: the current player, who
+// killed the last one, loses. The line is the one that killed it.
+func (r NotaktoRules) Outcome(g *Game, m Move) Outcome {
+	if len(r.LegalMoves(g)) > 0 {
+		return Outcome{}
+	}
+
+	boards := r.Boards(g)
+	c := boards.Unflatten(m.X, m.Y)
+	o := Outcome{Over: true, Loser: g.Current}
+	if line := boards.Layer(c.Z).CheckLineAt(c.X, c.Y); line.Winner != nil {
+		o.Line = flatLayerLine(boards, c.Z, line)
+	}
+	if opponents := g.Current.Opponents(g.Players); len(opponents) == 1 {
+		o.Winner = opponents[0]
+	}
+	return o
+}
+
+// flatLayerLine converts a line found on layer z of b3 to flat board
+// coordinates.
+func flatLayerLine(b3 *Board3D, z int, line WinResult) WinResult {
+	flat := WinResult{Winner: line.Winner, Direction: line.Direction}
+	for _, cell := range line.Cells {
+		x, y := b3.Flatten(cell.X, cell.Y, z)
+		flat.Cells = append(flat.Cells, Move{X: x, Y: y})
+	}
+	return flat
+}
@@ -0,0 +1,76 @@
+package game
+
+// Order and Chaos configuration.
+const (
+	OrderChaosBoardSize = 6 // Order and Chaos is played on a 6x6 board
+	OrderChaosToWin     = 5 // Order needs five in a row
+)
+
+// SymbolRules is implemented by rulesets where players may place the symbol
+// of another player, like OrderChaosRules. Such moves carry the chosen
+// symbol in Move.Mark.
+type SymbolRules interface {
+	Ruleset
+
+	// Marks lists the players whose symbols may be placed.
+	Marks(g *Game) []*Player
+}
+
+// OrderChaosRules implements Order and Chaos on top of StandardRules.
+//
+// The first player is Order and the others are Chaos. On each turn, the
+// current player places either the first or the second player's symbol.
+// Cells hold the player whose symbol was placed, so lines are found per
+// symbol: any line of Board.ToWin identical symbols wins the round for
+// Order, whoever completed it. If the board fills without one, Chaos wins
+// (with several Chaos players, Order loses the round instead).
+type OrderChaosRules struct {
+	StandardRules
+}
+
+// Marks returns the players whose symbols are in play: the first two.
+func (OrderChaosRules) Marks(g *Game) []*Player {
+	if len(g.Players) < 2 {
+		return g.Players
+	}
+	return g.Players[:2]
+}
+
+// LegalMoves returns every empty cell, once per symbol in play.
+func (r OrderChaosRules) LegalMoves(g *Game) []Move {
+	cells := r.StandardRules.LegalMoves(g)
+	marks := r.Marks(g)
+
+	moves := make([]Move, 0, len(cells)*len(marks))
+	for _, m := range cells {
+		for _, mark := range marks {
+			moves = append(moves, m.WithMark(mark))
+		}
+	}
+	return moves
+}
+
+// Outcome gives the round to Order on any line through the played cell,
+// and to Chaos once the board is full.
+func (r OrderChaosRules) Outcome(g *Game, m Move) Outcome {
+	order := r.Order(g)
+	if win := g.Board.CheckWinAt(m.X, m.Y); win.Winner != nil {
+		return Outcome{Over: true, Winner: order, Line: win}
+	}
+	if !g.Board.CheckDraw() {
+		return Outcome{}
+	}
+
+	if chaos := order.Opponents(g.Players); len(chaos) == 1 {
+		return Outcome{Over: true, Winner: chaos[0]}
+	}
+	return Outcome{Over: true, Loser: order}
+}
+
+// Order returns the player trying to make a line: the first player.
+func (OrderChaosRules) Order(g *Game) *Player {
+	if len(g.Players) == 0 {
+		return nil
+	}
+	return g.Players[0]
+}
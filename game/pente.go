@@ -0,0 +1,84 @@
+package game
+
+// Pente configuration.
+const (
+	PenteBoardSize     = 19 // Pente is played on a 19x19 board
+	PenteToWin         = 5  // Five in a row
+	PenteCapturesToWin = 5  // Captures needed to win the round
+	penteCaptureLength = 2  // Enemy marks removed by a capture
+)
+
+// PenteRules implements Pente-style custodial captures on top of
+// StandardRules.
+//
+// Bracketing exactly two marks of the same opponent between the placed
+// mark and another mark of the current player removes them from the board
+// and counts as one capture (Player.Captures). A player wins by aligning
+// Board.ToWin marks or by reaching PenteCapturesToWin captures.
+// Moving into a bracket is safe: only the placed mark can capture.
+type PenteRules struct {
+	StandardRules
+}
+
+// ApplyMove places the mark, then removes the pairs it captures.
+func (PenteRules) ApplyMove(g *Game, m Move) {
+	player := g.Current
+	g.Board.Play(player, m.X, m.Y)
+
+	for _, dir := range neighborDirections {
+		if captured := capturedBy(g.Board, player, m.X, m.Y, dir); captured != nil {
+			for _, c := range captured {
+				g.Board.Remove(c.X, c.Y)
+			}
+			player.Captures++
+		}
+	}
+}
+
+// Outcome checks the lines through the played cell, then the capture count,
+// then the draw condition.
+func (PenteRules) Outcome(g *Game, m Move) Outcome {
+	player := g.Board.Cells[m.X][m.Y]
+	if win := g.Board.CheckWinAt(m.X, m.Y); win.Winner != nil {
+		return Outcome{Over: true, Winner: win.Winner, Line: win}
+	}
+	if player != nil && player.Captures >= PenteCapturesToWin {
+		return Outcome{Over: true, Winner: player}
+	}
+	if g.Board.CheckDraw() {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// CapturesToWin returns the number of captures that wins the round.
+func (PenteRules) CapturesToWin() int {
+	return PenteCapturesToWin
+}
+
+// capturedBy returns the marks captured along dir by player's mark at (x, y):
+// exactly penteCaptureLength marks of a single opponent followed by a mark
+// of player. Returns nil if there is no capture in that direction.
+func capturedBy(b *Board, player *Player, x, y int, dir Direction) []Move {
+	captured := make([]Move, 0, penteCaptureLength)
+	var victim *Player
+
+	for step := firstStep; step <= penteCaptureLength; step++ {
+		cx, cy, ok := b.wrap(x+dir.DX*step, y+dir.DY*step)
+		if !ok {
+			return nil
+		}
+		owner := b.Cells[cx][cy]
+		if owner == nil || owner == player || (victim != nil && owner != victim) {
+			return nil
+		}
+		victim = owner
+		captured = append(captured, Move{X: cx, Y: cy})
+	}
+
+	ex, ey, ok := b.wrap(x+dir.DX*(penteCaptureLength+1), y+dir.DY*(penteCaptureLength+1))
+	if !ok || b.Cells[ex][ey] != player {
+		return nil
+	}
+	return captured
+}
@@ -3,19 +3,29 @@ package game
 import (
 	"GoTicTacToe/assets"
 	"image/color"
+	"slices"
 )
 
 // Player represents a participant in the game.
 //
 // A player can be either human-controlled or AI-controlled (IsAI flag).
 // The Symbol and Color fields are used for rendering, while Points
-// tracks the player's cumulative score across multiple rounds.
+// tracks the player's cumulative score across multiple rounds, Captures
+// the marks captured in the current round (see CaptureRules) and Lines the
+// lines completed in the current round (see Game.LineScoring).
+//
+// Players sharing a non-zero Team play together: they are not opponents,
+// their points are shared (see Game.TeamPoints) and, with Board.TeamLines,
+// their marks form lines together.
 type Player struct {
-	Symbol *assets.Symbol // Visual symbol rendered on the board
-	Points int            // Score accumulated across rounds
-	Color  color.Color    // Display color used in the UI
-	Name   string         // Display name (optional)
-	IsAI   bool           // True if controlled by an AI model
+	Symbol   *assets.Symbol // Visual symbol rendered on the board
+	Points   int            // Score accumulated across rounds
+	Captures int            // Captures made in the current round
+	Lines    int            // Lines completed in the current round
+	Team     int            // Team of the player (0 = playing alone)
+	Color    color.Color    // Display color used in the UI
+	Name     string         // Display name (optional)
+	IsAI     bool           // True if controlled by an AI model
 }
 
 // NewPlayer creates and returns a new player instance.
@@ -30,31 +40,68 @@ func NewPlayer(sym *assets.Symbol, c color.Color) *Player {
 	}
 }
 
+// IsTeammate reports whether q is another player of the same team.
+func (p *Player) IsTeammate(q *Player) bool {
+	return p != q && q != nil && p.Team != 0 && p.Team == q.Team
+}
+
+// SameSide reports whether q is this player or one of their teammates.
+func (p *Player) SameSide(q *Player) bool {
+	return p == q || p.IsTeammate(q)
+}
+
 // Opponent returns the first opponent of this player from the given list.
 //
 // This method assumes a two-player game and returns the first player
-// in the list that is not the receiver. Returns nil if no opponent is found.
+// in the list that is not the receiver nor a teammate. Returns nil if no
+// opponent is found.
 //
 // For games with more than two players, use Opponents instead.
 func (p *Player) Opponent(players []*Player) *Player {
 	for _, candidate := range players {
-		if candidate != p {
+		if !p.SameSide(candidate) {
 			return candidate
 		}
 	}
 	return nil
 }
 
-// Opponents returns all players in the list that are not this player.
+// Opponents returns all players in the list that are neither this player
+// nor one of their teammates.
 //
 // This is useful for multi-player scenarios where there may be more than
 // one opponent. The returned slice preserves the order from the input list.
 func (p *Player) Opponents(players []*Player) []*Player {
 	opponents := make([]*Player, 0, len(players)-1)
 	for _, candidate := range players {
-		if candidate != p {
+		if !p.SameSide(candidate) {
 			opponents = append(opponents, candidate)
 		}
 	}
 	return opponents
 }
+
+// TeamOrder returns the players in a turn order alternating the teams: the
+// first player of each side (a team, or a player without one), in order of
+// appearance, then the second player of each side, and so on.
+func TeamOrder(players []*Player) []*Player {
+	var sides [][]*Player
+	for _, p := range players {
+		i := slices.IndexFunc(sides, func(side []*Player) bool { return side[0].SameSide(p) })
+		if i < 0 {
+			sides = append(sides, []*Player{p})
+		} else {
+			sides[i] = append(sides[i], p)
+		}
+	}
+
+	order := make([]*Player, 0, len(players))
+	for round := 0; len(order) < len(players); round++ {
+		for _, side := range sides {
+			if round < len(side) {
+				order = append(order, side[round])
+			}
+		}
+	}
+	return order
+}
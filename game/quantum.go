@@ -0,0 +1,353 @@
+package game
+
+import (
+	"errors"
+	"slices"
+)
+
+// Quantum tic-tac-toe configuration.
+//
+// Points are counted in half points: the player completing the earliest
+// line earns QuantumWinPoints, and a player completing a later line at the
+// same time earns QuantumHalfPoints.
+const (
+	QuantumBoardSize  = 3 // Quantum tic-tac-toe is played on a 3x3 board
+	QuantumWinPoints  = 2 // One point, counted in half points
+	QuantumHalfPoints = 1 // Half a point
+)
+
+// Quantum tic-tac-toe move errors returned by QuantumRules.ValidateMove.
+var (
+	// ErrPairRequired is returned when a single cell is marked while a
+	// spooky move is expected.
+	ErrPairRequired = errors.New("game: a spooky move must mark two cells")
+
+	// ErrSameCell is returned when both marks of a spooky move target the same cell.
+	ErrSameCell = errors.New("game: the two marks of a spooky move must be in different cells")
+
+	// ErrCollapseRequired is returned when a spooky move is played while an
+	// entanglement cycle waits to be collapsed.
+	ErrCollapseRequired = errors.New("game: the entanglement cycle must be collapsed first")
+
+	// ErrNotCollapseCell is returned when a collapse does not choose one of
+	// the two cells of the mark closing the cycle.
+	ErrNotCollapseCell = errors.New("game: the mark must collapse into one of its two cells")
+)
+
+// SpookyMark is a mark of quantum tic-tac-toe, entangled between two cells
+// until it collapses into one of them.
+type SpookyMark struct {
+	Player *Player // Player who placed the mark
+	N      int     // Subscript: number of the move that placed it (1-based)
+	Cells  [2]Move // The two cells the mark may collapse into
+}
+
+// Other returns the cell of the mark other than c.
+func (s SpookyMark) Other(c Move) Move {
+	if s.Cells[0] == c {
+		return s.Cells[1]
+	}
+	return s.Cells[0]
+}
+
+// QuantumState is the quantum part of a game of quantum tic-tac-toe: the
+// spooky marks and the subscripts of the collapsed ones. It is derived
+// from the game history by QuantumRules.State.
+type QuantumState struct {
+	Spooky    []SpookyMark        // Marks not collapsed yet, oldest first
+	Classical map[Move]SpookyMark // Collapsed marks, by the cell they collapsed into
+	Pending   *SpookyMark         // Mark closing an entanglement cycle, to collapse next (nil if none)
+
+	moves int // Number of marks placed so far, giving the next subscript
+}
+
+// newQuantumState returns the state of a game without moves.
+func newQuantumState() *QuantumState {
+	return &QuantumState{Classical: map[Move]SpookyMark{}}
+}
+
+// clone returns an independent copy of the state.
+func (s *QuantumState) clone() *QuantumState {
+	c := &QuantumState{
+		Spooky:    slices.Clone(s.Spooky),
+		Classical: make(map[Move]SpookyMark, len(s.Classical)),
+		moves:     s.moves,
+	}
+	for cell, mark := range s.Classical {
+		c.Classical[cell] = mark
+	}
+	if s.Pending != nil {
+		pending := *s.Pending
+		c.Pending = &pending
+	}
+	return c
+}
+
+// MarksAt returns the spooky marks in cell c, oldest first.
+func (s *QuantumState) MarksAt(c Move) []SpookyMark {
+	var marks []SpookyMark
+	for _, mark := range s.Spooky {
+		if slices.Contains(mark.Cells[:], c) {
+			marks = append(marks, mark)
+		}
+	}
+	return marks
+}
+
+// play updates the state with move m of player and returns the cells that
+// became classical.
+//
+// A spooky move adds a mark, which becomes Pending if it closes a cycle.
+// A single cell collapses the Pending mark into it or, when no collapse is
+// pending, places a classical mark.
+func (s *QuantumState) play(player *Player, m Move) []Move {
+	cell := m.Cell()
+	if pair, ok := m.Pair(); ok {
+		s.moves++
+		mark := SpookyMark{Player: player, N: s.moves, Cells: [2]Move{cell, pair}}
+		if s.connected(cell, pair) {
+			s.Pending = &mark
+		}
+		s.Spooky = append(s.Spooky, mark)
+		return nil
+	}
+
+	if s.Pending != nil {
+		collapsed := s.collapse(*s.Pending, cell)
+		s.Pending = nil
+		return collapsed
+	}
+
+	s.moves++
+	s.Classical[cell] = SpookyMark{Player: player, N: s.moves, Cells: [2]Move{cell, cell}}
+	return []Move{cell}
+}
+
+// connected reports whether cells a and b are linked by a chain of
+// entangled spooky marks.
+func (s *QuantumState) connected(a, b Move) bool {
+	seen := map[Move]bool{a: true}
+	queue := []Move{a}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c == b {
+			return true
+		}
+		for _, mark := range s.MarksAt(c) {
+			if next := mark.Other(c); !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// collapse makes mark classical in cell, which forces every other spooky
+// mark in that cell into its other cell, and so on. It returns the cells
+// that became classical, in collapse order.
+func (s *QuantumState) collapse(mark SpookyMark, cell Move) []Move {
+	type step struct {
+		mark SpookyMark
+		cell Move
+	}
+
+	var collapsed []Move
+	queue := []step{{mark: mark, cell: cell}}
+	for len(queue) > 0 {
+		st := queue[0]
+		queue = queue[1:]
+
+		i := slices.IndexFunc(s.Spooky, func(m SpookyMark) bool { return m.N == st.mark.N })
+		if _, taken := s.Classical[st.cell]; i < 0 || taken {
+			continue
+		}
+		s.Spooky = slices.Delete(s.Spooky, i, i+1)
+		s.Classical[st.cell] = st.mark
+		collapsed = append(collapsed, st.cell)
+
+		for _, other := range s.MarksAt(st.cell) {
+			queue = append(queue, step{mark: other, cell: other.Other(st.cell)})
+		}
+	}
+	return collapsed
+}
+
+// QuantumRules implements Goff's quantum tic-tac-toe on top of
+// StandardRules.
+//
+// Each move places two entangled spooky marks, subscripted with the move
+// number, in two cells without a classical mark. When a move closes a cycle
+// of entanglements, the next player first collapses it by choosing the cell
+// of the mark closing the cycle, which fixes every mark of the cycle (and
+// the marks attached to it) as classical marks on the board; that player
+// then plays their own spooky move. When a single cell is left without a
+// classical mark, it is marked classically.
+//
+// Lines are made of classical marks. When a collapse completes lines for
+// several players, the line whose latest mark has the lowest subscript wins
+// QuantumWinPoints and the other players with a line get QuantumHalfPoints.
+// A board filled with classical marks without a line is a draw.
+type QuantumRules struct {
+	StandardRules
+}
+
+// State returns the quantum state of the game after the moves of its
+// history. The state after each move is cached in the history, so only the
+// moves played since the last call are replayed. The returned state is
+// shared and must not be modified.
+func (QuantumRules) State(g *Game) *QuantumState {
+	// Start from the latest state cached before the history cursor
+	start := g.cursor
+	for start > 0 && g.history[start-1].quantum == nil {
+		start--
+	}
+	s := newQuantumState()
+	if start > 0 {
+		s = g.history[start-1].quantum
+	}
+
+	for i := start; i < g.cursor; i++ {
+		s = s.clone()
+		rec := g.history[i].record
+		s.play(rec.Player, rec.Move())
+		g.history[i].quantum = s
+	}
+	return s
+}
+
+// NeedsPair reports whether the current player must play a spooky move,
+// i.e. no collapse is pending and several cells are free of classical marks.
+func (r QuantumRules) NeedsPair(g *Game) bool {
+	return r.State(g).Pending == nil && len(g.Board.AvailableMoves()) > 1
+}
+
+// LegalMoves returns the two cells of the pending collapse, if any, the
+// last free cell, or every pair of free cells.
+func (r QuantumRules) LegalMoves(g *Game) []Move {
+	if pending := r.State(g).Pending; pending != nil {
+		return []Move{pending.Cells[0], pending.Cells[1]}
+	}
+
+	free := g.Board.AvailableMoves()
+	if len(free) == 1 {
+		return free
+	}
+
+	var moves []Move
+	for i, a := range free {
+		for _, b := range free[i+1:] {
+			moves = append(moves, NewSpookyMove(a.X, a.Y, b.X, b.Y))
+		}
+	}
+	return moves
+}
+
+// ValidateMove checks that m collapses the pending mark into one of its
+// cells, marks the last free cell, or places a spooky pair on two different
+// cells free of classical marks.
+func (r QuantumRules) ValidateMove(g *Game, m Move) error {
+	pair, spooky := m.Pair()
+	if pending := r.State(g).Pending; pending != nil {
+		switch {
+		case spooky:
+			return ErrCollapseRequired
+		case !slices.Contains(pending.Cells[:], m.Cell()):
+			return ErrNotCollapseCell
+		default:
+			return nil
+		}
+	}
+
+	if !spooky {
+		if len(g.Board.AvailableMoves()) > 1 {
+			return ErrPairRequired
+		}
+		return r.StandardRules.ValidateMove(g, m)
+	}
+
+	switch {
+	case !g.Board.isValidPosition(m.X, m.Y) || !g.Board.isValidPosition(pair.X, pair.Y):
+		return ErrOutOfBounds
+	case pair == m.Cell():
+		return ErrSameCell
+	case g.Board.Cells[m.X][m.Y] != nil || g.Board.Cells[pair.X][pair.Y] != nil:
+		return ErrCellOccupied
+	default:
+		return nil
+	}
+}
+
+// ApplyMove places the classical marks resulting from m on the board.
+// Spooky marks are not stored on the board but derived from the history.
+func (r QuantumRules) ApplyMove(g *Game, m Move) {
+	s := r.State(g).clone()
+	for _, c := range s.play(g.Current, m) {
+		g.Board.Play(s.Classical[c].Player, c.X, c.Y)
+	}
+}
+
+// Outcome checks the classical lines once m added classical marks: the
+// line with the lowest latest subscript wins. A board full of classical
+// marks is a draw. The history does not include m yet.
+func (r QuantumRules) Outcome(g *Game, m Move) Outcome {
+	if m.HasPair {
+		return Outcome{}
+	}
+
+	s := r.State(g).clone()
+	s.play(g.Current, m)
+
+	var first WinResult
+	firstN := 0
+	for _, line := range g.Board.FindLines() {
+		n := 0
+		for _, c := range line.Cells {
+			n = max(n, s.Classical[c].N)
+		}
+		if first.Winner == nil || n < firstN {
+			first, firstN = line, n
+		}
+	}
+
+	if first.Winner != nil {
+		return Outcome{Over: true, Winner: first.Winner, Line: first}
+	}
+	if g.Board.CheckDraw() {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// Score gives QuantumWinPoints to the winner and QuantumHalfPoints to every
+// other player owning a line. A round without a winner but with a loser
+// gives QuantumWinPoints to every other player.
+func (QuantumRules) Score(g *Game, o Outcome) {
+	switch {
+	case o.Winner != nil:
+		o.Winner.Points += QuantumWinPoints
+		var rewarded []*Player
+		for _, line := range g.Board.FindLines() {
+			if line.Winner != o.Winner && !slices.Contains(rewarded, line.Winner) {
+				line.Winner.Points += QuantumHalfPoints
+				rewarded = append(rewarded, line.Winner)
+			}
+		}
+	case o.Loser != nil:
+		for _, p := range o.Loser.Opponents(g.Players) {
+			p.Points += QuantumWinPoints
+		}
+	}
+}
+
+// NextPlayer keeps the current player after a collapse, as they still have
+// to play their spooky move, and cycles through players otherwise. The
+// history does not include the move just played yet: a collapse is
+// recognized by a pending mark.
+func (r QuantumRules) NextPlayer(g *Game) *Player {
+	if r.State(g).Pending != nil {
+		return g.Current
+	}
+	return r.StandardRules.NextPlayer(g)
+}
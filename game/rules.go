@@ -0,0 +1,167 @@
+package game
+
+import "errors"
+
+// Move validation errors returned by Game.Play and Ruleset.ValidateMove.
+var (
+	// ErrGameOver is returned when a move is attempted after the round ended.
+	ErrGameOver = errors.New("game: the round is over")
+
+	// ErrOutOfBounds is returned when a move targets a cell outside the board.
+	ErrOutOfBounds = errors.New("game: move is outside the board")
+
+	// ErrCellOccupied is returned when a move targets a non-empty cell.
+	ErrCellOccupied = errors.New("game: cell is already occupied")
+
+	// ErrCellBlocked is returned when a move targets an obstacle or a cell
+	// outside of the board shape (see CellMask).
+	ErrCellBlocked = errors.New("game: cell cannot be played")
+
+	// ErrNotDropCell is returned, with gravity, when a move does not target
+	// the cell where a mark dropped in its column lands (see Board.DropRow).
+	ErrNotDropCell = errors.New("game: mark must be dropped where it lands in the column")
+
+	// ErrForeignMark is returned when a move places the symbol of another
+	// player and the rules do not allow it.
+	ErrForeignMark = errors.New("game: the symbol of another player cannot be placed")
+)
+
+// Outcome describes the state of a round after a move.
+type Outcome struct {
+	Over   bool      // True if the round has ended (win, loss or draw)
+	Winner *Player   // Winner of the round (nil for a draw or while playing)
+	Loser  *Player   // Player who lost the round on their own (misère), if any
+	Line   WinResult // Deciding alignment, if the rules define one
+}
+
+// Ruleset defines the rules of a game variant.
+//
+// Game delegates every rule decision to its Ruleset: which moves are legal,
+// what a move does to the board, when a round ends, how it is scored and who
+// plays next. Implementations should derive everything from the game they are
+// given (board, players, history) rather than keep their own state, so that
+// undo and redo keep working without extra bookkeeping.
+type Ruleset interface {
+	// LegalMoves lists every move the current player may play.
+	LegalMoves(g *Game) []Move
+
+	// ValidateMove returns nil if the current player may play m,
+	// or an error describing why the move is not allowed.
+	ValidateMove(g *Game, m Move) error
+
+	// ApplyMove performs a validated move m for the current player.
+	ApplyMove(g *Game, m Move)
+
+	// Outcome inspects the game right after m was applied.
+	Outcome(g *Game, m Move) Outcome
+
+	// Score awards points for a finished round.
+	Score(g *Game, o Outcome)
+
+	// NextPlayer returns the player who moves after the current one.
+	NextPlayer(g *Game) *Player
+}
+
+// LineRules is implemented by rulesets where a round is won by the first
+// player owning every cell of one of a fixed set of lines, like Rules3D.
+//
+// AI models can search such games without knowing their geometry.
+type LineRules interface {
+	Ruleset
+
+	// WinningLines lists every winning line as board cells.
+	WinningLines(g *Game) [][]Move
+}
+
+// CaptureRules is implemented by rulesets where players capture marks of
+// their opponents, like PenteRules. Captures are counted in Player.Captures
+// and reset at the start of every round.
+type CaptureRules interface {
+	Ruleset
+
+	// CapturesToWin returns the number of captures that wins the round.
+	CapturesToWin() int
+}
+
+// StandardRules implements the classic k-in-a-row rules: players take turns
+// in order placing one mark on an empty cell, the first to align Board.ToWin
+// marks wins the round and earns one point, and a full board is a draw.
+//
+// It is the Ruleset used by a Game whose Rules field is nil.
+type StandardRules struct{}
+
+// LegalMoves returns all empty cells of the board, or the landing cell of
+// each open column when the board has gravity.
+func (StandardRules) LegalMoves(g *Game) []Move {
+	return g.Board.AvailableMoves()
+}
+
+// ValidateMove checks that m targets an empty open cell inside the board
+// (the landing cell of its column when the board has gravity).
+func (StandardRules) ValidateMove(g *Game, m Move) error {
+	if !g.Board.inBounds(m.X, m.Y) {
+		return ErrOutOfBounds
+	}
+	if g.Board.MaskAt(m.X, m.Y) != CellOpen {
+		return ErrCellBlocked
+	}
+	if g.Board.Cells[m.X][m.Y] != nil {
+		return ErrCellOccupied
+	}
+	if g.Board.Gravity && m.Y != g.Board.DropRow(m.X) {
+		return ErrNotDropCell
+	}
+	return nil
+}
+
+// ApplyMove places the move's mark (by default the current player's) on
+// the target cell.
+func (StandardRules) ApplyMove(g *Game, m Move) {
+	g.Board.Play(m.MarkOf(g.Current), m.X, m.Y)
+}
+
+// Outcome checks the lines through the played cell, then the draw condition.
+func (StandardRules) Outcome(g *Game, m Move) Outcome {
+	if win := g.Board.CheckWinAt(m.X, m.Y); win.Winner != nil {
+		return Outcome{Over: true, Winner: win.Winner, Line: win}
+	}
+	if g.Board.CheckDraw() {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// Score gives one point to the winner, if any. A round without a winner but
+// with a loser gives one point to every other player.
+func (StandardRules) Score(g *Game, o Outcome) {
+	switch {
+	case o.Winner != nil:
+		o.Winner.Points++
+	case o.Loser != nil:
+		for _, p := range o.Loser.Opponents(g.Players) {
+			p.Points++
+		}
+	}
+}
+
+// NextPlayer cycles through players in the order of Game.Players.
+func (StandardRules) NextPlayer(g *Game) *Player {
+	return nextInOrder(g.Players, g.Current)
+}
+
+// nextInOrder returns the player following current in players.
+// If current is not found, the first player is returned.
+func nextInOrder(players []*Player, current *Player) *Player {
+	if len(players) == 0 {
+		return nil
+	}
+
+	for i, player := range players {
+		if player == current {
+			return players[(i+1)%len(players)]
+		}
+	}
+
+	// Fallback: current player not found in list, reset to first
+	return players[0]
+}
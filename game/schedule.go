@@ -0,0 +1,73 @@
+package game
+
+// TurnSchedule gives the number of marks a player places in each turn.
+//
+// Entry i applies to turn i+1 and the last entry repeats for every later
+// turn. A nil or empty schedule places one mark per turn, as in the
+// classic game.
+type TurnSchedule []int
+
+// Connect6Schedule places one stone on the first turn, then two per turn.
+var Connect6Schedule = TurnSchedule{1, 2}
+
+// Placements returns the number of marks placed in the given turn (1-based).
+// It is always at least one.
+func (s TurnSchedule) Placements(turn int) int {
+	if len(s) == 0 {
+		return 1
+	}
+
+	i := turn - 1
+	if i >= len(s) {
+		i = len(s) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	if s[i] < 1 {
+		return 1
+	}
+	return s[i]
+}
+
+// IsSingle reports whether every turn of the schedule places a single mark.
+func (s TurnSchedule) IsSingle() bool {
+	for _, n := range s {
+		if n > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Turn returns the number of the current turn (1-based).
+func (g *Game) Turn() int {
+	return g.turn
+}
+
+// TurnPlacements returns the number of marks placed in the current turn.
+func (g *Game) TurnPlacements() int {
+	return g.Schedule.Placements(g.turn)
+}
+
+// PlacementsLeft returns the number of marks the current player still has
+// to place before the turn passes, or 0 once the round is over.
+func (g *Game) PlacementsLeft() int {
+	if g.State != StatePlaying {
+		return 0
+	}
+	return g.TurnPlacements() - g.placed
+}
+
+// endPlacement counts a placement in the current turn and passes the turn
+// to the next player once the schedule is fulfilled.
+func (g *Game) endPlacement() {
+	g.placed++
+	if g.placed < g.TurnPlacements() {
+		return
+	}
+
+	g.placed = 0
+	g.turn++
+	g.NextPlayer()
+}
@@ -0,0 +1,133 @@
+package game
+
+import "errors"
+
+// Sliding variants, played on a 3x3 board: each player places this many
+// marks before sliding them.
+const (
+	SlidingBoardSize          = 3 // Achi and Three Men's Morris use a 3x3 board
+	AchiPlacements            = 4 // Achi: four marks each
+	ThreeMensMorrisPlacements = 3 // Three Men's Morris: three marks each
+)
+
+// Movement phase errors returned by SlidingRules.ValidateMove.
+var (
+	// ErrSlideRequired is returned when a mark is placed during the movement phase.
+	ErrSlideRequired = errors.New("game: a mark must be slid during the movement phase")
+
+	// ErrSlideNotAllowed is returned when a mark is slid during the placement phase.
+	ErrSlideNotAllowed = errors.New("game: marks cannot be slid before the movement phase")
+
+	// ErrNotOwnMark is returned when the source of a slide is not a mark of the current player.
+	ErrNotOwnMark = errors.New("game: only your own marks can be slid")
+
+	// ErrNotAdjacent is returned when a slide does not end on a neighboring cell.
+	ErrNotAdjacent = errors.New("game: a mark can only slide to an adjacent cell")
+)
+
+// MovementRules is implemented by rulesets with a movement phase, where
+// moves slide an existing mark instead of placing a new one.
+type MovementRules interface {
+	Ruleset
+
+	// Moving reports whether the current player must slide a mark.
+	Moving(g *Game) bool
+}
+
+// Moving reports whether the current player must slide a mark instead of
+// placing one, i.e. the rules are MovementRules in their movement phase.
+func (g *Game) Moving() bool {
+	rules, ok := g.Rules.(MovementRules)
+	return ok && g.State == StatePlaying && rules.Moving(g)
+}
+
+// SlidingRules implements Achi / Three Men's Morris style play on top of
+// StandardRules.
+//
+// Each player first places Placements marks. Once done, a move slides one
+// of their marks to an adjacent empty cell (including diagonally). Lines
+// win as usual. A player who cannot slide any mark ends the round in a draw.
+type SlidingRules struct {
+	StandardRules
+	Placements int // Marks placed by each player before the movement phase
+}
+
+// Moving reports whether the current player has placed all their marks.
+func (r SlidingRules) Moving(g *Game) bool {
+	return r.movingPlayer(g, g.Current)
+}
+
+// LegalMoves returns the empty cells during the placement phase, then every
+// slide of the current player's marks.
+func (r SlidingRules) LegalMoves(g *Game) []Move {
+	if r.Moving(g) {
+		return g.Board.SlideMoves(g.Current)
+	}
+	return r.StandardRules.LegalMoves(g)
+}
+
+// ValidateMove checks a placement like StandardRules during the placement
+// phase, and a slide of an own mark to an adjacent empty cell afterwards.
+func (r SlidingRules) ValidateMove(g *Game, m Move) error {
+	src, slide := m.Source()
+	if !r.Moving(g) {
+		if slide {
+			return ErrSlideNotAllowed
+		}
+		return r.StandardRules.ValidateMove(g, m)
+	}
+
+	switch {
+	case !slide:
+		return ErrSlideRequired
+	case !g.Board.isValidPosition(src.X, src.Y) || !g.Board.isValidPosition(m.X, m.Y):
+		return ErrOutOfBounds
+	case g.Board.Cells[src.X][src.Y] != g.Current:
+		return ErrNotOwnMark
+	case g.Board.Cells[m.X][m.Y] != nil:
+		return ErrCellOccupied
+	case !IsAdjacent(src.X, src.Y, m.X, m.Y):
+		return ErrNotAdjacent
+	default:
+		return nil
+	}
+}
+
+// ApplyMove places the mark, or slides it from its source cell.
+func (r SlidingRules) ApplyMove(g *Game, m Move) {
+	if src, ok := m.Source(); ok {
+		g.Board.Slide(src.X, src.Y, m.X, m.Y)
+		return
+	}
+	r.StandardRules.ApplyMove(g, m)
+}
+
+// Outcome checks the lines through the target cell, then whether the next
+// player is stuck in the movement phase (a draw), then a full board.
+func (r SlidingRules) Outcome(g *Game, m Move) Outcome {
+	if win := g.Board.CheckWinAt(m.X, m.Y); win.Winner != nil {
+		return Outcome{Over: true, Winner: win.Winner, Line: win}
+	}
+
+	next := r.NextPlayer(g)
+	if r.movingPlayer(g, next) && len(g.Board.SlideMoves(next)) == 0 {
+		return Outcome{Over: true}
+	}
+	if g.Board.CheckDraw() {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// movingPlayer reports whether player has placed all their marks.
+// Placements are counted from the history, which does not include the
+// move being played yet.
+func (r SlidingRules) movingPlayer(g *Game, player *Player) bool {
+	placed := 0
+	for _, rec := range g.History() {
+		if rec.Player == player && !rec.HasSource {
+			placed++
+		}
+	}
+	return placed >= r.Placements
+}
@@ -0,0 +1,315 @@
+package game
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Unbounded board configuration: five in a row wins, and the moves worth
+// considering are the empty cells at most UnboundedReach cells away from a
+// stone.
+const (
+	UnboundedToWin = 5 // Five in a row, as in Gomoku
+	UnboundedReach = 2 // Distance to the stones of the candidate moves
+)
+
+// SparseBoard is a board without edges, storing only the occupied cells.
+//
+// Cells have signed coordinates and the board grows as marks are placed:
+// Bounds returns the smallest rectangle holding every mark. Wins are only
+// looked for around the last placed mark (see CheckWinAt), so the cost of
+// a move does not depend on the size of the board.
+type SparseBoard struct {
+	ToWin int // Required consecutive symbols to win
+
+	cells                  map[Move]*Player // Occupied cells
+	minX, minY, maxX, maxY int              // Bounds of the occupied cells
+
+	journal   []cellChange // Cell changes recorded since startJournal
+	recording bool         // True while cell changes are being recorded
+}
+
+// NewSparseBoard returns an empty unbounded board.
+func NewSparseBoard(toWin int) *SparseBoard {
+	return &SparseBoard{ToWin: toWin, cells: map[Move]*Player{}}
+}
+
+// At returns the player owning cell (x, y), or nil.
+func (s *SparseBoard) At(x, y int) *Player {
+	return s.cells[Move{X: x, Y: y}]
+}
+
+// Play places the player's mark at (x, y). Returns false if the cell is
+// occupied.
+func (s *SparseBoard) Play(player *Player, x, y int) bool {
+	if s.At(x, y) != nil || player == nil {
+		return false
+	}
+	s.set(x, y, player)
+	return true
+}
+
+// Remove empties the cell (x, y). Returns false if it was already empty.
+func (s *SparseBoard) Remove(x, y int) bool {
+	if s.At(x, y) == nil {
+		return false
+	}
+	s.set(x, y, nil)
+	return true
+}
+
+// set assigns the cell (x, y), nil emptying it, keeps the bounds up to date
+// and records the change when journaling is active.
+func (s *SparseBoard) set(x, y int, player *Player) {
+	c := Move{X: x, Y: y}
+	if s.recording {
+		s.journal = append(s.journal, cellChange{X: x, Y: y, Before: s.cells[c], After: player})
+	}
+
+	if player == nil {
+		delete(s.cells, c)
+		// Only a mark on the edge of the bounds can shrink them
+		if x == s.minX || x == s.maxX || y == s.minY || y == s.maxY {
+			s.updateBounds()
+		}
+		return
+	}
+
+	if len(s.cells) == 0 {
+		s.minX, s.minY, s.maxX, s.maxY = x, y, x, y
+	} else {
+		s.minX, s.minY = min(s.minX, x), min(s.minY, y)
+		s.maxX, s.maxY = max(s.maxX, x), max(s.maxY, y)
+	}
+	s.cells[c] = player
+}
+
+// updateBounds computes the bounds of the occupied cells again.
+func (s *SparseBoard) updateBounds() {
+	first := true
+	for c := range s.cells {
+		if first {
+			s.minX, s.minY, s.maxX, s.maxY = c.X, c.Y, c.X, c.Y
+			first = false
+			continue
+		}
+		s.minX, s.minY = min(s.minX, c.X), min(s.minY, c.Y)
+		s.maxX, s.maxY = max(s.maxX, c.X), max(s.maxY, c.Y)
+	}
+}
+
+// startJournal begins recording cell changes.
+func (s *SparseBoard) startJournal() {
+	s.journal = nil
+	s.recording = true
+}
+
+// stopJournal stops recording and returns the changes made since startJournal.
+func (s *SparseBoard) stopJournal() []cellChange {
+	changes := s.journal
+	s.journal = nil
+	s.recording = false
+	return changes
+}
+
+// Len returns the number of marks on the board.
+func (s *SparseBoard) Len() int {
+	return len(s.cells)
+}
+
+// Bounds returns the smallest rectangle holding every mark, as its top
+// left and bottom right cells. The boolean is false on an empty board.
+func (s *SparseBoard) Bounds() (minX, minY, maxX, maxY int, ok bool) {
+	return s.minX, s.minY, s.maxX, s.maxY, len(s.cells) > 0
+}
+
+// Stones returns the occupied cells, column by column.
+func (s *SparseBoard) Stones() []Move {
+	stones := make([]Move, 0, len(s.cells))
+	for c := range s.cells {
+		stones = append(stones, c)
+	}
+	slices.SortFunc(stones, compareCells)
+	return stones
+}
+
+// Frontier returns the empty cells at most reach cells away (including
+// diagonally) from a mark, column by column. On an empty board, it
+// returns the origin.
+func (s *SparseBoard) Frontier(reach int) []Move {
+	if len(s.cells) == 0 {
+		return []Move{{}}
+	}
+
+	seen := map[Move]bool{}
+	var cells []Move
+	for c := range s.cells {
+		for dx := -reach; dx <= reach; dx++ {
+			for dy := -reach; dy <= reach; dy++ {
+				n := Move{X: c.X + dx, Y: c.Y + dy}
+				if s.cells[n] == nil && !seen[n] {
+					seen[n] = true
+					cells = append(cells, n)
+				}
+			}
+		}
+	}
+	slices.SortFunc(cells, compareCells)
+	return cells
+}
+
+// CheckWinAt looks for a winning alignment passing through cell (x, y),
+// scanning the four line directions from that cell only.
+func (s *SparseBoard) CheckWinAt(x, y int) WinResult {
+	player := s.At(x, y)
+	if player == nil {
+		return WinResult{}
+	}
+
+	target := max(s.ToWin, 1)
+	for _, dir := range winDirections {
+		back := s.countStreak(x, y, -dir.DX, -dir.DY, player, target-1)
+		forward := s.countStreak(x, y, dir.DX, dir.DY, player, target-1)
+
+		if back+initialStreakCount+forward >= target {
+			sx, sy := x-dir.DX*back, y-dir.DY*back
+			cells := make([]Move, target)
+			for i := range cells {
+				cells[i] = Move{X: sx + dir.DX*i, Y: sy + dir.DY*i}
+			}
+			return WinResult{Winner: player, Direction: dir, Cells: cells}
+		}
+	}
+	return WinResult{}
+}
+
+// CheckDraw returns false: the board never fills up.
+func (s *SparseBoard) CheckDraw() bool {
+	return false
+}
+
+// CompletesLine reports whether m, placing a mark of player or sliding a
+// mark, would complete a line through its target cell, reading the board
+// as if the move had been played.
+func (s *SparseBoard) CompletesLine(player *Player, m Move) bool {
+	src, slide := m.Source()
+	if slide {
+		player = s.At(src.X, src.Y)
+	}
+	if player == nil || s.At(m.X, m.Y) != nil {
+		return false
+	}
+
+	// Owner of cell (x, y) once m is played
+	after := func(x, y int) *Player {
+		switch {
+		case x == m.X && y == m.Y:
+			return player
+		case slide && x == src.X && y == src.Y:
+			return nil
+		default:
+			return s.At(x, y)
+		}
+	}
+	target := max(s.ToWin, 1)
+	streak := func(dx, dy int) int {
+		count := 0
+		for step := firstStep; step < target && after(m.X+dx*step, m.Y+dy*step) == player; step++ {
+			count++
+		}
+		return count
+	}
+
+	for _, dir := range winDirections {
+		if streak(-dir.DX, -dir.DY)+initialStreakCount+streak(dir.DX, dir.DY) >= target {
+			return true
+		}
+	}
+	return false
+}
+
+// FindWin looks for a winning alignment anywhere on the board.
+func (s *SparseBoard) FindWin() WinResult {
+	for _, c := range s.Stones() {
+		if win := s.CheckWinAt(c.X, c.Y); win.Winner != nil {
+			return win
+		}
+	}
+	return WinResult{}
+}
+
+// countStreak counts the consecutive marks of player from (x, y) along
+// (dx, dy), not counting (x, y) itself, up to limit.
+func (s *SparseBoard) countStreak(x, y, dx, dy int, player *Player, limit int) int {
+	count := 0
+	for step := firstStep; step <= limit; step++ {
+		if s.At(x+dx*step, y+dy*step) != player {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+// compareCells orders cells column by column, like Board.AvailableMoves.
+func compareCells(a, b Move) int {
+	return cmp.Or(cmp.Compare(a.X, b.X), cmp.Compare(a.Y, b.Y))
+}
+
+// UnboundedRules implements k-in-a-row on a board without edges: any empty
+// cell may be played, whatever its coordinates, and the round only ends
+// with a line. The marks are not stored on the game board, whose size only
+// matters for its ToWin, but on a SparseBoard provided to the game (see
+// BoardProvider and Sparse), which undo and redo update like the game
+// board. Gravity, wrapping and masks are not supported, and line scoring
+// is ignored.
+type UnboundedRules struct {
+	StandardRules
+}
+
+// Sparse returns the unbounded board of the game, holding the marks of the
+// current round.
+func (UnboundedRules) Sparse(g *Game) *SparseBoard {
+	s, _ := g.Store().(*SparseBoard)
+	return s
+}
+
+// NewBoard returns an empty unbounded board, with the ToWin of the game
+// board (UnboundedToWin without one).
+func (UnboundedRules) NewBoard(g *Game) CellStore {
+	toWin := UnboundedToWin
+	if g.Board != nil && g.Board.ToWin > 0 {
+		toWin = g.Board.ToWin
+	}
+	return NewSparseBoard(toWin)
+}
+
+// LegalMoves returns the empty cells near the marks already played (see
+// UnboundedReach), or the origin on an empty board. Every other empty cell
+// may be played too, but listing them is not possible.
+func (r UnboundedRules) LegalMoves(g *Game) []Move {
+	return r.Sparse(g).Frontier(UnboundedReach)
+}
+
+// ValidateMove checks that m targets an empty cell.
+func (r UnboundedRules) ValidateMove(g *Game, m Move) error {
+	if r.Sparse(g).At(m.X, m.Y) != nil {
+		return ErrCellOccupied
+	}
+	return nil
+}
+
+// ApplyMove places the mark on the unbounded board.
+func (r UnboundedRules) ApplyMove(g *Game, m Move) {
+	r.Sparse(g).Play(m.MarkOf(g.Current), m.X, m.Y)
+}
+
+// Outcome checks the lines through the played cell. The board never fills
+// up, so there is no draw.
+func (r UnboundedRules) Outcome(g *Game, m Move) Outcome {
+	s := r.Sparse(g)
+	if win := s.CheckWinAt(m.X, m.Y); win.Winner != nil {
+		return Outcome{Over: true, Winner: win.Winner, Line: win}
+	}
+	return Outcome{}
+}
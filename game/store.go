@@ -0,0 +1,62 @@
+package game
+
+// CellStore holds the marks of a round: who owns each cell.
+//
+// The game Board is the store of most rulesets. Rulesets implementing
+// BoardProvider keep the marks elsewhere, like the SparseBoard of
+// UnboundedRules. Game reads and writes the marks through the store for
+// mark limits, wins, draws, undo and redo, whatever the ruleset.
+type CellStore interface {
+	// At returns the player owning cell (x, y), nil if it is empty or
+	// outside of the board.
+	At(x, y int) *Player
+
+	// Remove empties the cell (x, y). Returns false if it was already empty.
+	Remove(x, y int) bool
+
+	// FindWin looks for a winning alignment anywhere on the board.
+	FindWin() WinResult
+
+	// CheckDraw returns true if the board is full.
+	CheckDraw() bool
+
+	// CompletesLine reports whether m, placing a mark of player or sliding
+	// a mark, would complete a line through its target cell. The store is
+	// left unchanged.
+	CompletesLine(player *Player, m Move) bool
+
+	// set assigns the cell (x, y), nil emptying it, and records the change
+	// when journaling is active.
+	set(x, y int, player *Player)
+
+	// startJournal begins recording cell changes.
+	startJournal()
+
+	// stopJournal stops recording and returns the changes made since
+	// startJournal.
+	stopJournal() []cellChange
+}
+
+// BoardProvider is implemented by rulesets keeping the marks of a round
+// elsewhere than on the game Board, like UnboundedRules.
+type BoardProvider interface {
+	Ruleset
+
+	// NewBoard returns an empty store for the marks of a new round.
+	NewBoard(g *Game) CellStore
+}
+
+// Store returns the store of the marks of the round: the one of the rules
+// when they implement BoardProvider, created empty when first needed, and
+// the game Board otherwise.
+func (g *Game) Store() CellStore {
+	provider, ok := g.Rules.(BoardProvider)
+	if !ok {
+		return g.Board
+	}
+
+	if g.store == nil {
+		g.store = provider.NewBoard(g)
+	}
+	return g.store
+}
@@ -0,0 +1,79 @@
+package game
+
+import "testing"
+
+func TestUnboundedUndoRedoThroughStore(t *testing.T) {
+	g := newTestGame(3, 3, 3, 2)
+	g.Rules = UnboundedRules{}
+	g.MaxMarks = 3
+
+	// X walks a line out to negative coordinates, losing its first mark
+	// to the limit on the way.
+	mustPlay(t, g,
+		Move{X: 5, Y: 0}, Move{X: 0, Y: 9},
+		Move{X: -1, Y: 0}, Move{X: 1, Y: 9},
+		Move{X: 0, Y: 0}, Move{X: 0, Y: 7},
+		Move{X: -2, Y: 0},
+	)
+	sparse := UnboundedRules{}.Sparse(g)
+	if g.Winner != g.Players[0] || sparse.At(5, 0) != nil {
+		t.Fatalf("winner %p, (5, 0) %p: want X winning without its first mark", g.Winner, sparse.At(5, 0))
+	}
+
+	for g.CanUndo() {
+		g.Undo()
+	}
+	if sparse.Len() != 0 {
+		t.Fatalf("%d marks left after undoing everything", sparse.Len())
+	}
+
+	for g.CanRedo() {
+		g.Redo()
+	}
+	if g.Winner != g.Players[0] || sparse.Len() != 6 || sparse.At(5, 0) != nil {
+		t.Fatalf("winner %p, %d marks: want the final position back", g.Winner, sparse.Len())
+	}
+}
+
+func TestCompletesLineLeavesStoreUnchanged(t *testing.T) {
+	x, o := NewPlayer(nil, nil), NewPlayer(nil, nil)
+	marks := map[Move]*Player{
+		{X: 0, Y: 0}: x, {X: 1, Y: 1}: x, {X: 4, Y: 0}: x,
+		{X: 2, Y: 0}: o, {X: 3, Y: 1}: o,
+	}
+
+	tests := []struct {
+		name   string
+		player *Player
+		move   Move
+		want   bool
+	}{
+		{"placement completing a diagonal", x, NewMove(2, 2), true},
+		{"placement next to marks", x, NewMove(0, 1), false},
+		{"placement of the other player", o, NewMove(2, 2), false},
+		{"placement on a mark", x, NewMove(2, 0), false},
+		{"slide completing a diagonal", nil, NewSlide(4, 0, 2, 2), true},
+		{"slide leaving its own line", nil, NewSlide(2, 0, 4, 2), false},
+		{"slide from an empty cell", nil, NewSlide(2, 2, 1, 2), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, store := range []CellStore{NewBoard(5, 5, 3), NewSparseBoard(3)} {
+				for cell, player := range marks {
+					store.set(cell.X, cell.Y, player)
+				}
+				if got := store.CompletesLine(tt.player, tt.move); got != tt.want {
+					t.Errorf("%T: CompletesLine = %v, want %v", store, got, tt.want)
+				}
+				for cell, player := range marks {
+					if store.At(cell.X, cell.Y) != player {
+						t.Fatalf("%T: cell %v changed", store, cell)
+					}
+				}
+				if store.At(tt.move.X, tt.move.Y) != marks[tt.move.Cell()] {
+					t.Fatalf("%T: target %v changed", store, tt.move.Cell())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package game
+
+import "errors"
+
+// Ultimate Tic-Tac-Toe configuration.
+const (
+	// UltimateSubSize is the size of a sub-board, which is also the size of
+	// the meta-board made of sub-boards.
+	UltimateSubSize = 3
+
+	// UltimateBoardSize is the size of the flat board holding every cell.
+	UltimateBoardSize = UltimateSubSize * UltimateSubSize
+)
+
+// Ultimate Tic-Tac-Toe move errors returned by UltimateRules.ValidateMove.
+var (
+	// ErrSubBoardClosed is returned when a move targets a sub-board that is
+	// already claimed or full.
+	ErrSubBoardClosed = errors.New("game: sub-board is already decided")
+
+	// ErrWrongSubBoard is returned when a move is not played in the sub-board
+	// designated by the previous move.
+	ErrWrongSubBoard = errors.New("game: move must be played in the active sub-board")
+)
+
+// UltimateBoard is a composite view of a Board as a grid of sub-boards.
+//
+// The underlying Board holds every cell in global coordinates, so moves,
+// history, undo and records keep working unchanged. Sub-board (sx, sy)
+// covers cells x in [sx*SubSize, (sx+1)*SubSize) and likewise for y.
+//
+// A sub-board is claimed by the first player aligning SubSize marks in it;
+// claimed and full sub-boards are closed. The meta-board holds the owner
+// of each sub-board and is won by aligning SubSize claimed sub-boards.
+type UltimateBoard struct {
+	*Board
+	SubSize int // Width and height of a sub-board (and of the meta-board)
+}
+
+// NewUltimateBoard returns the composite view of b with subSize x subSize
+// sub-boards. The board must be subSize*subSize cells wide and high.
+func NewUltimateBoard(b *Board, subSize int) *UltimateBoard {
+	return &UltimateBoard{Board: b, SubSize: subSize}
+}
+
+// SubBoardOf returns the coordinates of the sub-board containing cell (x, y).
+func (u *UltimateBoard) SubBoardOf(x, y int) (int, int) {
+	return x / u.SubSize, y / u.SubSize
+}
+
+// TargetOf returns the sub-board designated by a move played on (x, y):
+// the one at the same position in the meta-board as the cell in its sub-board.
+func (u *UltimateBoard) TargetOf(x, y int) (int, int) {
+	return x % u.SubSize, y % u.SubSize
+}
+
+// SubBoard returns a copy of sub-board (sx, sy) as a standalone Board.
+func (u *UltimateBoard) SubBoard(sx, sy int) *Board {
+	sub := NewBoard(u.SubSize, u.SubSize, u.SubSize)
+	for x := 0; x < u.SubSize; x++ {
+		for y := 0; y < u.SubSize; y++ {
+			sub.Cells[x][y] = u.Cells[sx*u.SubSize+x][sy*u.SubSize+y]
+		}
+	}
+	return sub
+}
+
+// Owner returns the player who claimed sub-board (sx, sy), or nil.
+func (u *UltimateBoard) Owner(sx, sy int) *Player {
+	return u.SubBoard(sx, sy).FindWin().Winner
+}
+
+// IsClosed returns true if sub-board (sx, sy) is claimed or full.
+func (u *UltimateBoard) IsClosed(sx, sy int) bool {
+	sub := u.SubBoard(sx, sy)
+	return sub.FindWin().Winner != nil || sub.CheckDraw()
+}
+
+// Meta returns the meta-board: one cell per sub-board holding its owner.
+func (u *UltimateBoard) Meta() *Board {
+	meta := NewBoard(u.SubSize, u.SubSize, u.SubSize)
+	for sx := 0; sx < u.SubSize; sx++ {
+		for sy := 0; sy < u.SubSize; sy++ {
+			meta.Cells[sx][sy] = u.Owner(sx, sy)
+		}
+	}
+	return meta
+}
+
+// ActiveSubBoards returns the sub-boards where the next mark may be played,
+// given the previous move (nil for the first move of a round).
+//
+// The previous move designates a single sub-board; if there is none, or if
+// the designated sub-board is closed, every open sub-board is active.
+func (u *UltimateBoard) ActiveSubBoards(last *Move) []Move {
+	if last != nil {
+		tx, ty := u.TargetOf(last.X, last.Y)
+		if !u.IsClosed(tx, ty) {
+			return []Move{{X: tx, Y: ty}}
+		}
+	}
+
+	var active []Move
+	for sx := 0; sx < u.SubSize; sx++ {
+		for sy := 0; sy < u.SubSize; sy++ {
+			if !u.IsClosed(sx, sy) {
+				active = append(active, Move{X: sx, Y: sy})
+			}
+		}
+	}
+	return active
+}
+
+// AvailableMoves returns the empty cells of the active sub-boards,
+// given the previous move (nil for the first move of a round).
+func (u *UltimateBoard) AvailableMoves(last *Move) []Move {
+	var moves []Move
+	for _, sub := range u.ActiveSubBoards(last) {
+		for x := sub.X * u.SubSize; x < (sub.X+1)*u.SubSize; x++ {
+			for y := sub.Y * u.SubSize; y < (sub.Y+1)*u.SubSize; y++ {
+				if u.Cells[x][y] == nil {
+					moves = append(moves, Move{X: x, Y: y})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// UltimateRules implements Ultimate Tic-Tac-Toe on a UltimateBoardSize board.
+//
+// Each move sends the opponent to the sub-board matching the cell just
+// played; a closed target leaves the choice free. Claiming a line of
+// sub-boards wins the round, and the round is a draw once every sub-board
+// is closed. The active sub-board is derived from the game history.
+type UltimateRules struct {
+	StandardRules
+}
+
+// Ultimate returns the composite view of the game board.
+func (UltimateRules) Ultimate(g *Game) *UltimateBoard {
+	return NewUltimateBoard(g.Board, UltimateSubSize)
+}
+
+// ActiveSubBoards returns the sub-boards where the current player may play.
+func (r UltimateRules) ActiveSubBoards(g *Game) []Move {
+	return r.Ultimate(g).ActiveSubBoards(lastPlaced(g))
+}
+
+// LegalMoves returns the empty cells of the active sub-boards.
+func (r UltimateRules) LegalMoves(g *Game) []Move {
+	return r.Ultimate(g).AvailableMoves(lastPlaced(g))
+}
+
+// ValidateMove checks that m targets an empty cell of an active sub-board.
+func (r UltimateRules) ValidateMove(g *Game, m Move) error {
+	if !g.Board.isValidPosition(m.X, m.Y) {
+		return ErrOutOfBounds
+	}
+	if g.Board.Cells[m.X][m.Y] != nil {
+		return ErrCellOccupied
+	}
+
+	u := r.Ultimate(g)
+	sx, sy := u.SubBoardOf(m.X, m.Y)
+	if u.IsClosed(sx, sy) {
+		return ErrSubBoardClosed
+	}
+	for _, active := range u.ActiveSubBoards(lastPlaced(g)) {
+		if active.X == sx && active.Y == sy {
+			return nil
+		}
+	}
+	return ErrWrongSubBoard
+}
+
+// Outcome checks whether the move claimed a sub-board completing a line of
+// the meta-board, then whether every sub-board is closed.
+//
+// The winning line lists every cell of the aligned sub-boards.
+func (r UltimateRules) Outcome(g *Game, m Move) Outcome {
+	u := r.Ultimate(g)
+	sx, sy := u.SubBoardOf(m.X, m.Y)
+
+	if win := u.Meta().CheckWinAt(sx, sy); win.Winner != nil {
+		line := WinResult{Winner: win.Winner, Direction: win.Direction}
+		for _, sub := range win.Cells {
+			for x := sub.X * u.SubSize; x < (sub.X+1)*u.SubSize; x++ {
+				for y := sub.Y * u.SubSize; y < (sub.Y+1)*u.SubSize; y++ {
+					line.Cells = append(line.Cells, Move{X: x, Y: y})
+				}
+			}
+		}
+		return Outcome{Over: true, Winner: win.Winner, Line: line}
+	}
+
+	if len(u.ActiveSubBoards(nil)) == 0 {
+		return Outcome{Over: true}
+	}
+	return Outcome{}
+}
+
+// lastPlaced returns the cell of the last applied move, or nil at the start of a round.
+func lastPlaced(g *Game) *Move {
+	last, ok := g.LastMove()
+	if !ok {
+		return nil
+	}
+	return &Move{X: last.X, Y: last.Y}
+}
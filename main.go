@@ -26,7 +26,9 @@
 package main
 
 import (
+	"GoTicTacToe/record"
 	"GoTicTacToe/screens"
+	"flag"
 	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -43,7 +45,12 @@ const (
 )
 
 // main initializes the window and starts the Ebiten game loop.
+//
+// The optional -load flag opens a saved match record instead of the menu.
 func main() {
+	loadPath := flag.String("load", "", "match record to open (.tgr)")
+	flag.Parse()
+
 	windowWidth := int(float64(baseWindowWidth) * windowScaleFactor)
 	windowHeight := int(float64(baseWindowHeight) * windowScaleFactor)
 
@@ -55,6 +62,18 @@ func main() {
 	host := screens.NewScreenHost()
 	host.SetScreen(screens.NewStartScreen(host))
 
+	if *loadPath != "" {
+		rec, err := record.ReadFile(*loadPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		gs, err := screens.NewGameScreenFromRecord(host, rec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		host.SetScreen(gs)
+	}
+
 	// Run the game.
 	if err := ebiten.RunGame(host); err != nil {
 		log.Fatal(err)
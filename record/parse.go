@@ -0,0 +1,726 @@
+package record
+
+import (
+	"GoTicTacToe/assets"
+	"GoTicTacToe/game"
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parsing limits.
+const (
+	// maxPlayers is the largest number of players accepted in a record.
+	maxPlayers = 8
+
+	// colorHexDigitsRGB and colorHexDigitsRGBA are the accepted color lengths (without '#').
+	colorHexDigitsRGB  = 6
+	colorHexDigitsRGBA = 8
+
+	// opaqueAlpha is the alpha used when a color omits it.
+	opaqueAlpha = 0xff
+)
+
+// ErrSyntax is wrapped by every error caused by malformed record text.
+var ErrSyntax = errors.New("record: syntax error")
+
+// Parse reads a record and validates it strictly.
+//
+// Unknown, duplicated or missing headers are rejected, as well as
+// unsupported versions. The move list is replayed through game.Game,
+// so illegal moves, wrong turn numbers, moves after the end of the game
+// and a Result header that does not match the replay are all errors.
+func Parse(r io.Reader) (*Record, error) {
+	headers, moveLines, err := readSections(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := decodeHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.Moves, err = decodeMoves(strings.Join(moveLines, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rec.validate(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Replay creates a new game from the record configuration and plays all
+// recorded moves on it. The players get their symbols, colors and names
+// from the record; callers are responsible for attaching AI models.
+func (r *Record) Replay() (*game.Game, error) {
+	players := make([]*game.Player, len(r.Players))
+	for i, info := range r.Players {
+		players[i] = game.NewPlayer(assets.NewSymbol(info.Symbol), info.Color)
+		players[i].Name = info.Name
+		players[i].Team = info.Team
+		players[i].IsAI = info.AI != ""
+	}
+
+	g := game.NewGameWithConfig(r.Width, r.Height, r.ToWin, players)
+	g.Board.Gravity = r.Gravity
+	g.Board.Wrap = r.Wrap
+	g.Board.TeamLines = r.TeamLines
+	g.Misere = r.Misere
+	g.LineScoring = r.LineScoring
+	g.MaxMarks = r.MaxMarks
+	g.Schedule = r.Schedule
+	g.Rules = r.Rules
+	g.Board.SetMask(r.Mask)
+	g.SetFirstPlayer(r.firstPlayer(players))
+	if err := r.Apply(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Apply plays the recorded moves on g, which must be a fresh game
+// with the same board configuration and number of players.
+func (r *Record) Apply(g *game.Game) error {
+	if g.Board.Width != r.Width || g.Board.Height != r.Height || g.Board.ToWin != r.ToWin {
+		return fmt.Errorf("record: board %dx%d (win %d) does not match game %dx%d (win %d)",
+			r.Width, r.Height, r.ToWin, g.Board.Width, g.Board.Height, g.Board.ToWin)
+	}
+	if g.Board.Gravity != r.Gravity {
+		return errors.New("record: gravity option does not match game")
+	}
+	if g.Board.Wrap != r.Wrap {
+		return errors.New("record: wrap option does not match game")
+	}
+	if g.Board.TeamLines != r.TeamLines {
+		return errors.New("record: team lines option does not match game")
+	}
+	if !r.sameMask(g.Board) {
+		return errors.New("record: board mask does not match game")
+	}
+	if g.Misere != r.Misere {
+		return errors.New("record: misère option does not match game")
+	}
+	if g.LineScoring != r.LineScoring {
+		return errors.New("record: line scoring option does not match game")
+	}
+	if !sameSchedule(g.Schedule, r.Schedule) {
+		return errors.New("record: turn schedule does not match game")
+	}
+	if g.MaxMarks != r.MaxMarks {
+		return fmt.Errorf("record: mark limit %d does not match game (%d)", r.MaxMarks, g.MaxMarks)
+	}
+	if g.RulesOrDefault() != r.rulesOrDefault() {
+		return errors.New("record: rule set does not match game")
+	}
+	if len(g.Players) != len(r.Players) {
+		return fmt.Errorf("record: %d players recorded but game has %d", len(r.Players), len(g.Players))
+	}
+	for i, p := range g.Players {
+		if p.Team != r.Players[i].Team {
+			return fmt.Errorf("record: team of player %d does not match game", i+1)
+		}
+	}
+	if g.FirstPlayer() != r.firstPlayer(g.Players) {
+		return errors.New("record: first player does not match game")
+	}
+	if len(g.History()) != 0 {
+		return errors.New("record: game already has moves")
+	}
+
+	for i, mv := range r.Moves {
+		coord := formatMove(mv)
+		if err := g.Play(mv.gameMove(g.Players)); err != nil {
+			return fmt.Errorf("record: move %d (%s) is illegal: %w", i+1, coord, err)
+		}
+
+		history := g.History()
+		if played := history[len(history)-1]; played.Turn != mv.Turn {
+			return fmt.Errorf("record: move %d (%s) belongs to turn %d, not %d", i+1, coord, played.Turn, mv.Turn)
+		}
+	}
+
+	if r.TimeOut {
+		if !g.IsPlaying() {
+			return errors.New("record: time out after the end of the game")
+		}
+		g.TimeOut(g.Current)
+	}
+
+	if result := New(g).Result; result != r.Result {
+		return fmt.Errorf("record: result %q does not match replayed result %q", r.Result, result)
+	}
+	return nil
+}
+
+// validate replays the record on a game without rendering resources.
+func (r *Record) validate() error {
+	players := make([]*game.Player, len(r.Players))
+	for i, info := range r.Players {
+		players[i] = game.NewPlayer(nil, info.Color)
+		players[i].Name = info.Name
+		players[i].Team = info.Team
+	}
+
+	g := game.NewGameWithConfig(r.Width, r.Height, r.ToWin, players)
+	g.Board.Gravity = r.Gravity
+	g.Board.Wrap = r.Wrap
+	g.Board.TeamLines = r.TeamLines
+	g.Misere = r.Misere
+	g.LineScoring = r.LineScoring
+	g.MaxMarks = r.MaxMarks
+	g.Schedule = r.Schedule
+	g.Rules = r.Rules
+	g.Board.SetMask(r.Mask)
+	g.SetFirstPlayer(r.firstPlayer(players))
+	return r.Apply(g)
+}
+
+// firstPlayer returns the player of players moving first, players being
+// those of the record.
+func (r *Record) firstPlayer(players []*game.Player) *game.Player {
+	if r.FirstPlayer > 0 && r.FirstPlayer <= len(players) {
+		return players[r.FirstPlayer-1]
+	}
+	if len(players) == 0 {
+		return nil
+	}
+	return players[0]
+}
+
+// header is a single parsed "[Key "Value"]" line.
+type header struct {
+	key   string
+	value string
+	line  int
+}
+
+// readSections splits the input into header lines and move text lines.
+func readSections(r io.Reader) ([]header, []string, error) {
+	var headers []header
+	var moves []string
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	inMoves := false
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		if !inMoves {
+			if line == "" {
+				inMoves = len(headers) > 0
+				continue
+			}
+			h, err := parseHeader(line, lineNo)
+			if err != nil {
+				return nil, nil, err
+			}
+			headers = append(headers, h)
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			return nil, nil, fmt.Errorf("%w: line %d: header after move list", ErrSyntax, lineNo)
+		}
+		moves = append(moves, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(headers) == 0 {
+		return nil, nil, fmt.Errorf("%w: missing headers", ErrSyntax)
+	}
+	return headers, moves, nil
+}
+
+// parseHeader decodes a header line of the form [Key "Value"].
+func parseHeader(line string, lineNo int) (header, error) {
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return header{}, fmt.Errorf("%w: line %d: expected header", ErrSyntax, lineNo)
+	}
+	body := line[1 : len(line)-1]
+
+	key, quoted, found := strings.Cut(body, " ")
+	if !found || key == "" {
+		return header{}, fmt.Errorf("%w: line %d: malformed header", ErrSyntax, lineNo)
+	}
+
+	value, err := unquote(quoted)
+	if err != nil {
+		return header{}, fmt.Errorf("%w: line %d: %v", ErrSyntax, lineNo, err)
+	}
+	return header{key: key, value: value, line: lineNo}, nil
+}
+
+// unquote decodes a double-quoted header value with \" and \\ escapes.
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", errors.New("value must be quoted")
+	}
+
+	var sb strings.Builder
+	inner := s[1 : len(s)-1]
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case c == '\\':
+			i++
+			if i >= len(inner) || (inner[i] != '\\' && inner[i] != '"') {
+				return "", errors.New("invalid escape sequence")
+			}
+			sb.WriteByte(inner[i])
+		case c == '"':
+			return "", errors.New("unescaped quote in value")
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), nil
+}
+
+// decodeHeaders builds a record from the parsed headers.
+func decodeHeaders(headers []header) (*Record, error) {
+	values := make(map[string]string, len(headers))
+	for _, h := range headers {
+		if _, dup := values[h.key]; dup {
+			return nil, fmt.Errorf("%w: line %d: duplicate header %q", ErrSyntax, h.line, h.key)
+		}
+		values[h.key] = h.value
+	}
+
+	if headers[0].key != "Format" || headers[0].value != FormatName {
+		return nil, fmt.Errorf("%w: first header must be [Format %q]", ErrSyntax, FormatName)
+	}
+
+	// take fetches a mandatory header and marks it as consumed.
+	take := func(key string) (string, error) {
+		v, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("%w: missing header %q", ErrSyntax, key)
+		}
+		delete(values, key)
+		return v, nil
+	}
+	takeInt := func(key string, lo, hi int) (int, error) {
+		v, err := take(key)
+		if err != nil {
+			return 0, err
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < lo || n > hi {
+			return 0, fmt.Errorf("%w: header %q must be an integer in [%d, %d]", ErrSyntax, key, lo, hi)
+		}
+		return n, nil
+	}
+	// takeOption fetches an optional on/off header, absent meaning off.
+	takeOption := func(key string) (bool, error) {
+		v, ok := values[key]
+		if !ok {
+			return false, nil
+		}
+		delete(values, key)
+		if v != optionOn {
+			return false, fmt.Errorf("%w: header %q must be %q when present", ErrSyntax, key, optionOn)
+		}
+		return true, nil
+	}
+
+	if _, err := take("Format"); err != nil {
+		return nil, err
+	}
+	if _, err := takeInt("Version", 1, FormatVersion); err != nil {
+		return nil, fmt.Errorf("record: unsupported version: %w", err)
+	}
+
+	rec := &Record{}
+	var err error
+	if rec.Width, err = takeInt("Width", 1, maxColumns); err != nil {
+		return nil, err
+	}
+	if rec.Height, err = takeInt("Height", 1, maxColumns); err != nil {
+		return nil, err
+	}
+	if rec.ToWin, err = takeInt("ToWin", 1, maxColumns); err != nil {
+		return nil, err
+	}
+	if rec.Gravity, err = takeOption("Gravity"); err != nil {
+		return nil, err
+	}
+	if rec.Wrap, err = takeOption("Wrap"); err != nil {
+		return nil, err
+	}
+	if rec.TeamLines, err = takeOption("TeamLines"); err != nil {
+		return nil, err
+	}
+	if rec.Misere, err = takeOption("Misere"); err != nil {
+		return nil, err
+	}
+	if rec.LineScoring, err = takeOption("LineScoring"); err != nil {
+		return nil, err
+	}
+	if _, ok := values["MaxMarks"]; ok {
+		if rec.MaxMarks, err = takeInt("MaxMarks", 1, maxColumns*maxColumns); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := values["Schedule"]; ok {
+		delete(values, "Schedule")
+		if rec.Schedule, err = parseSchedule(v); err != nil {
+			return nil, fmt.Errorf("%w: header %q: %v", ErrSyntax, "Schedule", err)
+		}
+	}
+	if name, ok := values["Rules"]; ok {
+		delete(values, "Rules")
+		if rec.Rules, err = parseRules(name); err != nil {
+			return nil, fmt.Errorf("%w: header %q: %v", ErrSyntax, "Rules", err)
+		}
+	}
+	if v, ok := values["Mask"]; ok {
+		delete(values, "Mask")
+		if rec.Mask, err = parseMask(v, rec.Width, rec.Height); err != nil {
+			return nil, fmt.Errorf("%w: header %q: %v", ErrSyntax, "Mask", err)
+		}
+	}
+	playerCount, err := takeInt("Players", 1, maxPlayers)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 1; i <= playerCount; i++ {
+		key := fmt.Sprintf("Player%d", i)
+		var info PlayerInfo
+
+		if info.Name, err = take(key); err != nil {
+			return nil, err
+		}
+		symbol, err := take(key + "Symbol")
+		if err != nil {
+			return nil, err
+		}
+		if info.Symbol, err = parseSymbol(symbol); err != nil {
+			return nil, fmt.Errorf("%w: header %q: %v", ErrSyntax, key+"Symbol", err)
+		}
+		hex, err := take(key + "Color")
+		if err != nil {
+			return nil, err
+		}
+		if info.Color, err = parseColor(hex); err != nil {
+			return nil, fmt.Errorf("%w: header %q: %v", ErrSyntax, key+"Color", err)
+		}
+		if info.AI, err = take(key + "AI"); err != nil {
+			return nil, err
+		}
+		if _, ok := values[key+"Team"]; ok {
+			if info.Team, err = takeInt(key+"Team", 1, maxPlayers); err != nil {
+				return nil, err
+			}
+		}
+
+		rec.Players = append(rec.Players, info)
+	}
+	if _, ok := values["FirstPlayer"]; ok {
+		if rec.FirstPlayer, err = takeInt("FirstPlayer", 1, playerCount); err != nil {
+			return nil, err
+		}
+	}
+	if rec.TimeOut, err = takeOption("TimeOut"); err != nil {
+		return nil, err
+	}
+
+	if rec.Result, err = take("Result"); err != nil {
+		return nil, err
+	}
+	if err := checkResult(rec.Result, playerCount); err != nil {
+		return nil, err
+	}
+
+	for _, h := range headers {
+		if _, unknown := values[h.key]; unknown {
+			return nil, fmt.Errorf("%w: line %d: unknown header %q", ErrSyntax, h.line, h.key)
+		}
+	}
+	return rec, nil
+}
+
+// checkResult verifies that a Result header has one of the allowed values.
+func checkResult(result string, playerCount int) error {
+	if result == ResultOngoing || result == ResultDraw {
+		return nil
+	}
+	index := strings.TrimPrefix(result, ResultLoserPrefix)
+	n, err := strconv.Atoi(index)
+	if err != nil || n < 1 || n > playerCount || strconv.Itoa(n) != index {
+		return fmt.Errorf("%w: invalid result %q", ErrSyntax, result)
+	}
+	return nil
+}
+
+// decodeMoves parses the move text, e.g. "1. a1 2. b2".
+func decodeMoves(text string) ([]Move, error) {
+	var moves []Move
+	turn := 0
+	needMove := false
+
+	for _, tok := range strings.Fields(text) {
+		if num, ok := strings.CutSuffix(tok, "."); ok {
+			n, err := strconv.Atoi(num)
+			if err != nil || strconv.Itoa(n) != num {
+				return nil, fmt.Errorf("%w: invalid turn number %q", ErrSyntax, tok)
+			}
+			if needMove {
+				return nil, fmt.Errorf("%w: turn %d has no moves", ErrSyntax, turn)
+			}
+			if n != turn+1 {
+				return nil, fmt.Errorf("%w: turn %d follows turn %d", ErrSyntax, n, turn)
+			}
+			turn = n
+			needMove = true
+			continue
+		}
+
+		if turn == 0 {
+			return nil, fmt.Errorf("%w: move %q before first turn number", ErrSyntax, tok)
+		}
+		mv, err := parseMove(tok)
+		if err != nil {
+			return nil, err
+		}
+		mv.Turn = turn
+		moves = append(moves, mv)
+		needMove = false
+	}
+
+	if needMove {
+		return nil, fmt.Errorf("%w: turn %d has no moves", ErrSyntax, turn)
+	}
+	return moves, nil
+}
+
+// parseMove decodes a move written in record notation: a cell, the
+// source and target cells of a slide, for example "a1-b2", or the two
+// cells of a spooky move, for example "a1+c3", optionally
+// followed by the player whose symbol is placed, for example "c3=2".
+func parseMove(tok string) (Move, error) {
+	tok, markIndex, hasMark := cutMove(tok, markSeparator)
+	mv, err := parseCells(tok)
+	if err != nil || !hasMark {
+		return mv, err
+	}
+
+	mark, err := strconv.Atoi(markIndex)
+	if err != nil || mark < 1 || mark > maxPlayers || strconv.Itoa(mark) != markIndex {
+		return Move{}, fmt.Errorf("%w: invalid symbol index in move %q", ErrSyntax, tok+markSeparator+markIndex)
+	}
+	mv.Mark = mark
+	return mv, nil
+}
+
+// parseCells decodes the cells of a move: a cell, the source and target
+// cells of a slide, or the two cells of a spooky move.
+func parseCells(tok string) (Move, error) {
+	if first, second, spooky := cutMove(tok, pairSeparator); spooky {
+		x, y, err := ParseCoord(first)
+		if err != nil {
+			return Move{}, err
+		}
+		px, py, err := ParseCoord(second)
+		if err != nil {
+			return Move{}, err
+		}
+		return Move{X: x, Y: y, PairX: px, PairY: py, HasPair: true}, nil
+	}
+
+	from, to, slide := cutMove(tok, slideSeparator)
+	if !slide {
+		x, y, err := ParseCoord(tok)
+		return Move{X: x, Y: y}, err
+	}
+
+	fx, fy, err := ParseCoord(from)
+	if err != nil {
+		return Move{}, err
+	}
+	x, y, err := ParseCoord(to)
+	if err != nil {
+		return Move{}, err
+	}
+	return Move{X: x, Y: y, FromX: fx, FromY: fy, HasSource: true}, nil
+}
+
+// cutMove is strings.Cut for the move notation: separators between the
+// parentheses of a cell written as its coordinates, such as the minus sign
+// of "(-2,30)", are skipped.
+func cutMove(tok, sep string) (before, after string, found bool) {
+	depth := 0
+	for i := 0; i < len(tok); i++ {
+		switch {
+		case strings.HasPrefix(tok[i:], coordOpen):
+			depth++
+		case strings.HasPrefix(tok[i:], coordClose):
+			depth--
+		case depth == 0 && strings.HasPrefix(tok[i:], sep):
+			return tok[:i], tok[i+len(sep):], true
+		}
+	}
+	return tok, "", false
+}
+
+// ParseCoord decodes a cell written in record notation, for example "b3",
+// or as its coordinates, for example "(-2,30)" (see FormatCoord).
+func ParseCoord(s string) (int, int, error) {
+	if inner, ok := strings.CutPrefix(s, coordOpen); ok {
+		return parseSignedCoord(s, inner)
+	}
+	if len(s) < 2 || s[0] < 'a' || s[0] >= 'a'+maxColumns {
+		return 0, 0, fmt.Errorf("%w: invalid cell %q", ErrSyntax, s)
+	}
+	row, err := strconv.Atoi(s[1:])
+	if err != nil || row < 1 || strconv.Itoa(row) != s[1:] {
+		return 0, 0, fmt.Errorf("%w: invalid cell %q", ErrSyntax, s)
+	}
+	return int(s[0] - 'a'), row - 1, nil
+}
+
+// parseSignedCoord decodes the coordinates of cell s, written as "(x,y)",
+// given the text following the opening parenthesis.
+func parseSignedCoord(s, inner string) (int, int, error) {
+	inner, closed := strings.CutSuffix(inner, coordClose)
+	xs, ys, ok := strings.Cut(inner, coordSeparator)
+	if !closed || !ok {
+		return 0, 0, fmt.Errorf("%w: invalid cell %q", ErrSyntax, s)
+	}
+	x, errX := strconv.Atoi(xs)
+	y, errY := strconv.Atoi(ys)
+	if errX != nil || errY != nil || strconv.Itoa(x) != xs || strconv.Itoa(y) != ys {
+		return 0, 0, fmt.Errorf("%w: invalid cell %q", ErrSyntax, s)
+	}
+	return x, y, nil
+}
+
+// parseSymbol returns the symbol with the given record name.
+func parseSymbol(name string) (assets.SymbolType, error) {
+	for sym, n := range symbolNames {
+		if n == name {
+			return sym, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown symbol %q", name)
+}
+
+// parseRules returns the rule set recorded under name.
+func parseRules(name string) (game.Ruleset, error) {
+	if rules, ok := rulesByName[name]; ok {
+		return rules, nil
+	}
+	return nil, fmt.Errorf("unknown rule set %q", name)
+}
+
+// rulesOrDefault returns the recorded rule set, StandardRules if none.
+func (r *Record) rulesOrDefault() game.Ruleset {
+	if r.Rules == nil {
+		return game.StandardRules{}
+	}
+	return r.Rules
+}
+
+// parseSchedule decodes a comma-separated list of placements per turn.
+func parseSchedule(s string) (game.TurnSchedule, error) {
+	var schedule game.TurnSchedule
+	for _, field := range strings.Split(s, scheduleSeparator) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > maxColumns*maxColumns {
+			return nil, fmt.Errorf("invalid turn schedule %q", s)
+		}
+		schedule = append(schedule, n)
+	}
+	return schedule, nil
+}
+
+// sameSchedule reports whether two turn schedules place the same number
+// of marks in every turn.
+func sameSchedule(a, b game.TurnSchedule) bool {
+	n := max(len(a), len(b))
+	for turn := 1; turn <= n; turn++ {
+		if a.Placements(turn) != b.Placements(turn) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMask decodes the rows of a Mask header for a width x height board.
+func parseMask(s string, width, height int) ([][]game.CellMask, error) {
+	rows := strings.Split(s, maskRowSeparator)
+	if len(rows) != height {
+		return nil, fmt.Errorf("mask has %d rows, board has %d", len(rows), height)
+	}
+
+	mask := make([][]game.CellMask, width)
+	for x := range mask {
+		mask[x] = make([]game.CellMask, height)
+	}
+	for y, row := range rows {
+		if len(row) != width {
+			return nil, fmt.Errorf("mask row %d has %d cells, board has %d columns", y+1, len(row), width)
+		}
+		for x := 0; x < width; x++ {
+			m, ok := parseMaskChar(row[x])
+			if !ok {
+				return nil, fmt.Errorf("invalid mask cell %q", row[x])
+			}
+			mask[x][y] = m
+		}
+	}
+	return mask, nil
+}
+
+// parseMaskChar returns the cell mask written as c in a Mask header.
+func parseMaskChar(c byte) (game.CellMask, bool) {
+	for m, char := range maskChars {
+		if char == c {
+			return m, true
+		}
+	}
+	return game.CellOpen, false
+}
+
+// sameMask reports whether every cell of b has the mask recorded for it.
+func (r *Record) sameMask(b *game.Board) bool {
+	for x := 0; x < r.Width; x++ {
+		for y := 0; y < r.Height; y++ {
+			if b.MaskAt(x, y) != r.maskAt(x, y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseColor decodes a #rrggbb or #rrggbbaa color.
+func parseColor(s string) (color.RGBA, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || (len(hex) != colorHexDigitsRGB && len(hex) != colorHexDigitsRGBA) {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if strings.ToLower(hex) != hex {
+		return color.RGBA{}, fmt.Errorf("color %q must be lower case", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	if len(hex) == colorHexDigitsRGB {
+		v = v<<8 | opaqueAlpha
+	}
+	return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
@@ -0,0 +1,294 @@
+package record
+
+import (
+	"GoTicTacToe/game"
+	"errors"
+	"image/color"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestGame returns a game of n players, with teams when given.
+func newTestGame(width, height, toWin, n int, teams ...int) *game.Game {
+	players := make([]*game.Player, n)
+	for i := range players {
+		players[i] = game.NewPlayer(nil, color.RGBA{R: uint8(40 * i), A: 0xff})
+		if i < len(teams) {
+			players[i].Team = teams[i]
+		}
+	}
+	return game.NewGameWithConfig(width, height, toWin, players)
+}
+
+// cellsOf returns the owner of each cell of g, by player index.
+func cellsOf(g *game.Game) [][]int {
+	cells := make([][]int, len(g.Board.Cells))
+	for x, column := range g.Board.Cells {
+		for _, owner := range column {
+			cells[x] = append(cells[x], slices.Index(g.Players, owner))
+		}
+	}
+	return cells
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func() *game.Game
+		moves []game.Move
+		marks []int              // Index of the player whose symbol each move places (nil = the mover's)
+		end   func(g *game.Game) // Run after the moves (nil = none)
+	}{
+		{
+			name:  "win",
+			setup: func() *game.Game { return newTestGame(3, 3, 3, 2) },
+			moves: []game.Move{game.NewMove(0, 0), game.NewMove(0, 1), game.NewMove(1, 0), game.NewMove(1, 1), game.NewMove(2, 0)},
+		},
+		{
+			name: "misère loser of three",
+			setup: func() *game.Game {
+				g := newTestGame(3, 3, 3, 3)
+				g.Misere = true
+				return g
+			},
+			moves: []game.Move{
+				game.NewMove(0, 0), game.NewMove(0, 1), game.NewMove(2, 2),
+				game.NewMove(1, 0), game.NewMove(1, 1), game.NewMove(2, 1),
+				game.NewMove(2, 0),
+			},
+		},
+		{
+			name: "wrap",
+			setup: func() *game.Game {
+				g := newTestGame(4, 4, 3, 2)
+				g.Board.Wrap = true
+				return g
+			},
+			moves: []game.Move{game.NewMove(3, 0), game.NewMove(3, 1), game.NewMove(0, 0), game.NewMove(0, 1), game.NewMove(1, 0)},
+		},
+		{
+			name: "mask",
+			setup: func() *game.Game {
+				g := newTestGame(3, 3, 3, 2)
+				g.Board.SetCellMask(1, 1, game.CellBlocked)
+				g.Board.SetCellMask(0, 0, game.CellVoid)
+				return g
+			},
+			moves: []game.Move{game.NewMove(1, 0), game.NewMove(2, 2)},
+		},
+		{
+			name: "Connect6 schedule, second player first",
+			setup: func() *game.Game {
+				g := newTestGame(7, 7, 6, 2)
+				g.Schedule = game.Connect6Schedule
+				g.SetFirstPlayer(g.Players[1])
+				return g
+			},
+			moves: []game.Move{game.NewMove(3, 3), game.NewMove(0, 0), game.NewMove(0, 1), game.NewMove(3, 4)},
+		},
+		{
+			name: "mark limit and time out",
+			setup: func() *game.Game {
+				g := newTestGame(4, 4, 4, 2)
+				g.MaxMarks = 2
+				return g
+			},
+			moves: []game.Move{game.NewMove(0, 0), game.NewMove(3, 3), game.NewMove(1, 0), game.NewMove(2, 3), game.NewMove(2, 0)},
+			end:   func(g *game.Game) { g.TimeOut(g.Current) },
+		},
+		{
+			name: "teams and line scoring",
+			setup: func() *game.Game {
+				g := newTestGame(4, 4, 3, 4, 1, 2, 1, 2)
+				g.Board.TeamLines = true
+				g.LineScoring = true
+				return g
+			},
+			moves: []game.Move{game.NewMove(0, 0), game.NewMove(0, 1), game.NewMove(1, 0), game.NewMove(1, 1), game.NewMove(2, 0)},
+		},
+		{
+			name: "slides",
+			setup: func() *game.Game {
+				g := newTestGame(3, 3, 3, 2)
+				g.Rules = game.SlidingRules{Placements: game.AchiPlacements}
+				return g
+			},
+			moves: []game.Move{
+				game.NewMove(0, 0), game.NewMove(2, 2), game.NewMove(2, 0), game.NewMove(1, 0),
+				game.NewMove(0, 2), game.NewMove(0, 1), game.NewMove(2, 1), game.NewMove(1, 2),
+				game.NewSlide(2, 1, 1, 1),
+			},
+		},
+		{
+			name: "order and chaos",
+			setup: func() *game.Game {
+				g := newTestGame(6, 6, 5, 2)
+				g.Rules = game.OrderChaosRules{}
+				return g
+			},
+			moves: []game.Move{game.NewMove(0, 0), game.NewMove(1, 0), game.NewMove(2, 0)},
+			marks: []int{0, 0, 1},
+		},
+		{
+			name: "quantum",
+			setup: func() *game.Game {
+				g := newTestGame(3, 3, 3, 2)
+				g.Rules = game.QuantumRules{}
+				return g
+			},
+			moves: []game.Move{
+				game.NewSpookyMove(0, 0, 1, 1), game.NewSpookyMove(0, 1, 1, 2),
+				game.NewSpookyMove(0, 0, 1, 1), game.NewMove(0, 0),
+			},
+		},
+		{
+			name: "unbounded",
+			setup: func() *game.Game {
+				g := newTestGame(3, 3, 3, 2)
+				g.Rules = game.UnboundedRules{}
+				return g
+			},
+			moves: []game.Move{game.NewMove(-2, 30), game.NewMove(0, 0), game.NewMove(-1, 30), game.NewMove(40, 0), game.NewMove(-3, 30)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.setup()
+			for i, m := range tt.moves {
+				if tt.marks != nil {
+					m = m.WithMark(g.Players[tt.marks[i]])
+				}
+				if err := g.Play(m); err != nil {
+					t.Fatalf("playing %v: %v", m, err)
+				}
+			}
+			if tt.end != nil {
+				tt.end(g)
+			}
+
+			text := New(g).String()
+			rec, err := Parse(strings.NewReader(text))
+			if err != nil {
+				t.Fatalf("Parse() error = %v\n%s", err, text)
+			}
+			replayed, err := rec.Replay()
+			if err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+
+			if got := New(replayed).String(); got != text {
+				t.Errorf("replayed record differs:\n got %s\nwant %s", got, text)
+			}
+			if !slices.EqualFunc(cellsOf(replayed), cellsOf(g), slices.Equal) {
+				t.Errorf("replayed board differs")
+			}
+			if replayed.State != g.State || slices.Index(replayed.Players, replayed.Current) != slices.Index(g.Players, g.Current) {
+				t.Errorf("replayed game state differs")
+			}
+		})
+	}
+}
+
+// validRecord is a record of a round won by the first player.
+const validRecord = `[Format "TicTacGo"]
+[Version "1"]
+[Width "3"]
+[Height "3"]
+[ToWin "3"]
+[Players "2"]
+[Player1 "Alice"]
+[Player1Symbol "circle"]
+[Player1Color "#ff6384"]
+[Player1AI ""]
+[Player2 "Bob"]
+[Player2Symbol "cross"]
+[Player2Color "#36a2eb"]
+[Player2AI "minimax"]
+[Result "1"]
+
+1. a1 2. a2 3. b1 4. b2 5. c1
+`
+
+func TestParseValid(t *testing.T) {
+	rec, err := Parse(strings.NewReader(validRecord))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(rec.Moves) != 5 || rec.Players[1].AI != "minimax" || rec.Result != "1" {
+		t.Errorf("Parse() = %+v", rec)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		old    string // Text of validRecord to replace
+		new    string
+		syntax bool  // The error wraps ErrSyntax
+		target error // Error wrapped by the parse error (nil = none checked)
+	}{
+		{name: "format not first", old: "[Format \"TicTacGo\"]\n[Version \"1\"]", new: "[Version \"1\"]\n[Format \"TicTacGo\"]", syntax: true},
+		{name: "other format", old: `"TicTacGo"`, new: `"PGN"`, syntax: true},
+		{name: "unsupported version", old: `[Version "1"]`, new: `[Version "2"]`, syntax: true},
+		{name: "duplicate header", old: `[ToWin "3"]`, new: `[ToWin "3"]` + "\n" + `[ToWin "3"]`, syntax: true},
+		{name: "unknown header", old: `[ToWin "3"]`, new: `[ToWin "3"]` + "\n" + `[Event "?"]`, syntax: true},
+		{name: "missing header", old: `[Player2AI "minimax"]` + "\n", new: "", syntax: true},
+		{name: "bad integer", old: `[Width "3"]`, new: `[Width "three"]`, syntax: true},
+		{name: "unquoted value", old: `[Width "3"]`, new: `[Width 3]`, syntax: true},
+		{name: "bad option", old: `[ToWin "3"]`, new: `[ToWin "3"]` + "\n" + `[Gravity "yes"]`, syntax: true},
+		{name: "bad symbol", old: `"circle"`, new: `"star"`, syntax: true},
+		{name: "bad color", old: `"#ff6384"`, new: `"red"`, syntax: true},
+		{name: "bad result", old: `[Result "1"]`, new: `[Result "3"]`, syntax: true},
+		{name: "header after moves", old: "5. c1\n", new: "5. c1\n[Result \"1\"]\n", syntax: true},
+		{name: "bad move", old: "5. c1", new: "5. z9", target: game.ErrOutOfBounds},
+		{name: "occupied cell", old: "4. b2", new: "4. a1", target: game.ErrCellOccupied},
+		{name: "move after the end", old: "5. c1", new: "5. c1 6. c3", target: game.ErrGameOver},
+		{name: "wrong turn", old: "5. c1", new: "c1"},
+		{name: "result mismatch", old: `[Result "1"]`, new: `[Result "2"]`},
+		{name: "time out after the end", old: `[Result "1"]`, new: `[TimeOut "on"]` + "\n" + `[Result "1"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(validRecord, tt.old) {
+				t.Fatalf("%q not found in the valid record", tt.old)
+			}
+			text := strings.Replace(validRecord, tt.old, tt.new, 1)
+
+			_, err := Parse(strings.NewReader(text))
+			switch {
+			case err == nil:
+				t.Fatalf("Parse() accepted:\n%s", text)
+			case errors.Is(err, ErrSyntax) != tt.syntax:
+				t.Errorf("Parse() error = %v, syntax error %v, want %v", err, errors.Is(err, ErrSyntax), tt.syntax)
+			case tt.target != nil && !errors.Is(err, tt.target):
+				t.Errorf("Parse() error = %v, want %v", err, tt.target)
+			}
+		})
+	}
+}
+
+// sliceRules is a rule set that cannot be compared, as it holds a slice.
+type sliceRules struct {
+	game.StandardRules
+	Banned []game.Move
+}
+
+func TestRulesNames(t *testing.T) {
+	for name, rules := range rulesByName {
+		if got, ok := rulesName(rules); !ok || got != name {
+			t.Errorf("rulesName(%T) = %q, %v, want %q", rules, got, ok, name)
+		}
+	}
+
+	for _, rules := range []game.Ruleset{sliceRules{}, game.Rules3D{Depth: 2}} {
+		g := newTestGame(3, 3, 3, 2)
+		g.Rules = rules
+		if err := New(g).Encode(io.Discard); err == nil {
+			t.Errorf("Encode() recorded the rule set %T", rules)
+		}
+	}
+}
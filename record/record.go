@@ -0,0 +1,548 @@
+// Package record implements a versioned, PGN-style text format used to
+// save, load, archive and share Tic-Tac-Toe matches.
+//
+// A record starts with a block of headers, one per line, followed by an
+// empty line and the move list:
+//
+//	[Format "TicTacGo"]
+//	[Version "1"]
+//	[Width "3"]
+//	[Height "3"]
+//	[ToWin "3"]
+//	[Players "2"]
+//	[Player1 "Player 1"]
+//	[Player1Symbol "circle"]
+//	[Player1Color "#ff6384"]
+//	[Player1AI ""]
+//	[Player2 "Player 2"]
+//	[Player2Symbol "cross"]
+//	[Player2Color "#36a2eb"]
+//	[Player2AI "minimax"]
+//	[Result "1"]
+//
+//	1. a1 2. b2 3. a2 4. c3 5. a3
+//
+// Optional headers describe rule options and are only written when the
+// option is enabled:
+//
+//	[Gravity "on"]
+//	[Wrap "on"]
+//	[TeamLines "on"]
+//	[Misere "on"]
+//	[LineScoring "on"]
+//
+// MaxMarks gives the number of marks each player may hold when it is
+// limited, the oldest mark vanishing when one more is placed:
+//
+//	[MaxMarks "3"]
+//
+// The team of a player, when they play in one, follows their AI header:
+//
+//	[Player1Team "1"]
+//
+// TimeOut is written when the round ended with the player to move running
+// out of time, after the last recorded move:
+//
+//	[TimeOut "on"]
+//
+// FirstPlayer gives the 1-based index of the player who moved first, when
+// it is not the first player (in a match, where the first move rotates):
+//
+//	[FirstPlayer "2"]
+//
+// Schedule lists the number of marks placed in each turn when players may
+// place more than one, the last number repeating (Connect6 below). Moves
+// of the same turn share its turn number in the move list:
+//
+//	[Schedule "1,2"]
+//
+// The Rules header names the rule set when it is not the classic one:
+//
+//	[Rules "renju"]
+//
+// Mask describes the cells of a board that cannot be played, row by row
+// from the top, rows being separated by "/": "." is an open cell, "#" an
+// obstacle and "x" a cell outside of the board shape. It is only written
+// when some cell is not open:
+//
+//	[Mask "x.x/.#./x.x"]
+//
+// Moves are written as a column letter followed by a 1-based row number,
+// rows being counted from the top of the board. A slide is written as its
+// source and target cells joined by "-", for example "a1-b2". A move
+// placing the symbol of another player (Order and Chaos) is followed by
+// "=" and the index of that player, for example "c3=2". A spooky move of
+// quantum tic-tac-toe is written as its two cells joined by "+", for
+// example "a1+c3". On unbounded boards, cells that have no such name, left
+// of the first column, above the first row or right of column "z", are
+// written as their 0-based column and row between parentheses, for example
+// "(-2,30)". Each move is preceded by the number of the turn it belongs
+// to. Result is "*" for an unfinished game, "draw" for a draw, or the
+// 1-based index of the winning player.
+// A misère round lost by one of three or more players, which has no single
+// winner, is written as "-" followed by the index of the losing player.
+package record
+
+import (
+	"GoTicTacToe/assets"
+	"GoTicTacToe/game"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Format identification written in every record.
+const (
+	// FormatName is the value of the mandatory Format header.
+	FormatName = "TicTacGo"
+
+	// FormatVersion is the record version produced by this package.
+	FormatVersion = 1
+
+	// FileExtension is the conventional extension of record files.
+	FileExtension = ".tgr"
+)
+
+// Result values for records without a winner.
+const (
+	// ResultOngoing marks a record of a game that has not ended.
+	ResultOngoing = "*"
+
+	// ResultDraw marks a record of a drawn game.
+	ResultDraw = "draw"
+
+	// ResultLoserPrefix precedes the index of the losing player in the
+	// result of a misère round without a single winner.
+	ResultLoserPrefix = "-"
+)
+
+// optionOn is the value of an enabled optional header.
+const optionOn = "on"
+
+// scheduleSeparator separates the turns of the Schedule header.
+const scheduleSeparator = ","
+
+// slideSeparator separates the source and target cells of a slide.
+const slideSeparator = "-"
+
+// pairSeparator separates the two cells of a spooky move.
+const pairSeparator = "+"
+
+// markSeparator precedes the player whose symbol a move places.
+const markSeparator = "="
+
+// Delimiters of a cell written as its coordinates, for example "(-2,30)".
+const (
+	coordOpen      = "("
+	coordSeparator = ","
+	coordClose     = ")"
+)
+
+// maskRowSeparator separates the rows of the Mask header.
+const maskRowSeparator = "/"
+
+// maskChars maps every cell mask to its character in the Mask header.
+var maskChars = map[game.CellMask]byte{
+	game.CellOpen:    '.',
+	game.CellBlocked: '#',
+	game.CellVoid:    'x',
+}
+
+// Record layout limits.
+const (
+	// maxColumns is the number of columns that can be written as a single letter.
+	maxColumns = 26
+
+	// recordFileMode is the permission used when writing record files.
+	recordFileMode = 0o644
+)
+
+// rulesByName maps the name of every rule set that can be recorded to the
+// rule set. StandardRules is the default and has no name.
+var rulesByName = map[string]game.Ruleset{
+	"gomoku":            game.GomokuRules{Variant: game.GomokuFreestyle},
+	"gomoku-standard":   game.GomokuRules{Variant: game.GomokuStandard},
+	"renju":             game.GomokuRules{Variant: game.GomokuRenju},
+	"ultimate":          game.UltimateRules{},
+	"qubic":             game.Rules3D{Depth: game.QubicSize},
+	"pente":             game.PenteRules{},
+	"achi":              game.SlidingRules{Placements: game.AchiPlacements},
+	"three-mens-morris": game.SlidingRules{Placements: game.ThreeMensMorrisPlacements},
+	"order-chaos":       game.OrderChaosRules{},
+	"notakto":           game.NotaktoRules{},
+	"quantum":           game.QuantumRules{},
+	"hex":               game.HexRules{},
+	"unbounded":         game.UnboundedRules{},
+}
+
+// rulesName returns the name rules are recorded under. Rule sets of other
+// types than the recorded ones are never compared, as they may not be
+// comparable at all, and cannot be recorded.
+func rulesName(rules game.Ruleset) (string, bool) {
+	switch rules.(type) {
+	case game.GomokuRules, game.UltimateRules, game.Rules3D, game.PenteRules,
+		game.SlidingRules, game.OrderChaosRules, game.NotaktoRules,
+		game.QuantumRules, game.HexRules, game.UnboundedRules:
+	default:
+		return "", false
+	}
+
+	for name, known := range rulesByName {
+		if known == rules {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// symbolNames maps every symbol to its name in a record.
+var symbolNames = map[assets.SymbolType]string{
+	assets.CrossSymbol:    "cross",
+	assets.CircleSymbol:   "circle",
+	assets.TriangleSymbol: "triangle",
+	assets.SquareSymbol:   "square",
+}
+
+// Move is a single recorded move.
+type Move struct {
+	Turn int // Turn number the move belongs to (1-based)
+	X    int // Column of the move
+	Y    int // Row of the move
+
+	FromX     int  // Column the mark slid from (slides only)
+	FromY     int  // Row the mark slid from (slides only)
+	HasSource bool // True for a slide, false for a placement
+
+	PairX   int  // Column of the second cell (spooky moves only)
+	PairY   int  // Row of the second cell (spooky moves only)
+	HasPair bool // True for a spooky move marking two cells
+
+	Mark int // 1-based index of the player whose symbol is placed (0 = the mover's)
+}
+
+// gameMove returns the game move described by the recorded move,
+// played in a game with the given players.
+func (mv Move) gameMove(players []*game.Player) game.Move {
+	m := game.NewMove(mv.X, mv.Y)
+	if mv.HasSource {
+		m = game.NewSlide(mv.FromX, mv.FromY, mv.X, mv.Y)
+	}
+	if mv.HasPair {
+		m = game.NewSpookyMove(mv.X, mv.Y, mv.PairX, mv.PairY)
+	}
+	if mv.Mark > 0 && mv.Mark <= len(players) {
+		m.Mark = players[mv.Mark-1]
+	}
+	return m
+}
+
+// PlayerInfo describes one participant of a recorded match.
+type PlayerInfo struct {
+	Name   string            // Display name
+	Symbol assets.SymbolType // Symbol drawn on the board
+	Color  color.RGBA        // Display color
+	AI     string            // AI model name, empty for a human player
+	Team   int               // Team of the player (0 = playing alone)
+}
+
+// Record is the in-memory form of a saved match.
+type Record struct {
+	Width       int               // Number of columns
+	Height      int               // Number of rows
+	ToWin       int               // Required consecutive symbols to win
+	Gravity     bool              // Marks fall to the lowest empty row
+	Wrap        bool              // Lines continue across the edges
+	TeamLines   bool              // Marks of teammates count together in lines
+	Misere      bool              // Completing a line loses the round
+	LineScoring bool              // Every line scores a point, the round going on until the board is full
+	MaxMarks    int               // Marks each player may hold (0 = unlimited)
+	Schedule    game.TurnSchedule // Marks placed in each turn (nil = one)
+	Rules       game.Ruleset      // Rule set, nil for the classic rules
+	Mask        [][]game.CellMask // Playability of each cell, like game.Board.Mask (nil = every cell open)
+	Players     []PlayerInfo      // Participants, in turn order
+	FirstPlayer int               // 1-based index of the player moving first (0 = the first player)
+	Moves       []Move            // Moves in the order they were played
+	TimeOut     bool              // The player to move after the last move ran out of time
+	Result      string            // ResultOngoing, ResultDraw or the 1-based winner index
+}
+
+// New creates a record of the given game: its configuration,
+// its players and the moves currently in its history.
+//
+// AI model names are not known by the game and are left empty;
+// callers can fill PlayerInfo.AI afterwards.
+func New(g *game.Game) *Record {
+	rec := &Record{
+		Width:       g.Board.Width,
+		Height:      g.Board.Height,
+		ToWin:       g.Board.ToWin,
+		Gravity:     g.Board.Gravity,
+		Wrap:        g.Board.Wrap,
+		TeamLines:   g.Board.TeamLines,
+		Misere:      g.Misere,
+		LineScoring: g.LineScoring,
+		MaxMarks:    g.MaxMarks,
+		Schedule:    g.Schedule,
+		Rules:       g.Rules,
+		Result:      ResultOngoing,
+	}
+	if g.Board.IsMasked() {
+		rec.Mask = g.Board.Clone().Mask
+	}
+
+	for _, mv := range g.History() {
+		rec.Moves = append(rec.Moves, Move{
+			Turn: mv.Turn, X: mv.X, Y: mv.Y,
+			FromX: mv.FromX, FromY: mv.FromY, HasSource: mv.HasSource,
+			PairX: mv.PairX, PairY: mv.PairY, HasPair: mv.HasPair,
+			Mark: slices.Index(g.Players, mv.Mark) + 1,
+		})
+	}
+
+	if first := slices.Index(g.Players, g.FirstPlayer()); first > 0 {
+		rec.FirstPlayer = first + 1
+	}
+
+	for _, p := range g.Players {
+		info := PlayerInfo{Name: p.Name, Team: p.Team}
+		if p.Symbol != nil {
+			info.Symbol = p.Symbol.Type
+		}
+		if p.Color != nil {
+			info.Color = toRGBA(p.Color)
+		}
+		rec.Players = append(rec.Players, info)
+	}
+
+	rec.TimeOut = g.TimedOut() != nil
+	if g.IsGameEnd() {
+		rec.Result = ResultDraw
+		for i, p := range g.Players {
+			switch {
+			case p == g.Winner:
+				rec.Result = strconv.Itoa(i + 1)
+			case p == g.Loser && g.Winner == nil:
+				rec.Result = ResultLoserPrefix + strconv.Itoa(i+1)
+			}
+		}
+	}
+
+	return rec
+}
+
+// Encode writes the record in text form to w.
+func (r *Record) Encode(w io.Writer) error {
+	var sb strings.Builder
+
+	writeHeader(&sb, "Format", FormatName)
+	writeHeader(&sb, "Version", strconv.Itoa(FormatVersion))
+	writeHeader(&sb, "Width", strconv.Itoa(r.Width))
+	writeHeader(&sb, "Height", strconv.Itoa(r.Height))
+	writeHeader(&sb, "ToWin", strconv.Itoa(r.ToWin))
+	if r.Gravity {
+		writeHeader(&sb, "Gravity", optionOn)
+	}
+	if r.Wrap {
+		writeHeader(&sb, "Wrap", optionOn)
+	}
+	if r.TeamLines {
+		writeHeader(&sb, "TeamLines", optionOn)
+	}
+	if r.Misere {
+		writeHeader(&sb, "Misere", optionOn)
+	}
+	if r.LineScoring {
+		writeHeader(&sb, "LineScoring", optionOn)
+	}
+	if r.MaxMarks > 0 {
+		writeHeader(&sb, "MaxMarks", strconv.Itoa(r.MaxMarks))
+	}
+	if !r.Schedule.IsSingle() {
+		writeHeader(&sb, "Schedule", formatSchedule(r.Schedule))
+	}
+	if !isStandard(r.Rules) {
+		name, ok := rulesName(r.Rules)
+		if !ok {
+			return fmt.Errorf("record: rule set %T cannot be recorded", r.Rules)
+		}
+		writeHeader(&sb, "Rules", name)
+	}
+	if r.isMasked() {
+		writeHeader(&sb, "Mask", r.formatMask())
+	}
+	writeHeader(&sb, "Players", strconv.Itoa(len(r.Players)))
+
+	for i, p := range r.Players {
+		key := fmt.Sprintf("Player%d", i+1)
+		symbol, ok := symbolNames[p.Symbol]
+		if !ok {
+			return fmt.Errorf("record: player %d has unknown symbol %d", i+1, p.Symbol)
+		}
+
+		writeHeader(&sb, key, p.Name)
+		writeHeader(&sb, key+"Symbol", symbol)
+		writeHeader(&sb, key+"Color", formatColor(p.Color))
+		writeHeader(&sb, key+"AI", p.AI)
+		if p.Team > 0 {
+			writeHeader(&sb, key+"Team", strconv.Itoa(p.Team))
+		}
+	}
+	if r.FirstPlayer > 1 {
+		writeHeader(&sb, "FirstPlayer", strconv.Itoa(r.FirstPlayer))
+	}
+	if r.TimeOut {
+		writeHeader(&sb, "TimeOut", optionOn)
+	}
+
+	writeHeader(&sb, "Result", r.Result)
+	sb.WriteString("\n")
+
+	lastTurn := 0
+	for i, mv := range r.Moves {
+		coord := formatMove(mv)
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		if mv.Turn != lastTurn {
+			fmt.Fprintf(&sb, "%d. ", mv.Turn)
+			lastTurn = mv.Turn
+		}
+		sb.WriteString(coord)
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// String returns the record in text form.
+func (r *Record) String() string {
+	var sb strings.Builder
+	if err := r.Encode(&sb); err != nil {
+		return ""
+	}
+	return sb.String()
+}
+
+// WriteFile encodes the record into the file at path.
+func (r *Record) WriteFile(path string) error {
+	var sb strings.Builder
+	if err := r.Encode(&sb); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(sb.String()), recordFileMode)
+}
+
+// ReadFile parses the record stored in the file at path.
+func ReadFile(path string) (*Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(strings.NewReader(string(data)))
+}
+
+// FormatCoord returns the record notation of cell (x, y), for example "b3".
+// Cells that cannot be written with a letter and a row number, found on
+// unbounded boards, are written as their coordinates between parentheses,
+// for example "(-2,30)".
+func FormatCoord(x, y int) string {
+	if x < 0 || x >= maxColumns || y < 0 {
+		return coordOpen + strconv.Itoa(x) + coordSeparator + strconv.Itoa(y) + coordClose
+	}
+	return fmt.Sprintf("%c%d", 'a'+x, y+1)
+}
+
+// formatMove returns the record notation of a move: its cell, preceded by
+// the source cell and slideSeparator for a slide, for example "a1-b2", or
+// followed by pairSeparator and the second cell for a spooky move, for
+// example "a1+c3", and followed by markSeparator and a player index when
+// the move places the symbol of that player, for example "c3=2".
+func formatMove(mv Move) string {
+	coord := FormatCoord(mv.X, mv.Y)
+	if mv.HasSource {
+		coord = FormatCoord(mv.FromX, mv.FromY) + slideSeparator + coord
+	}
+	if mv.HasPair {
+		coord += pairSeparator + FormatCoord(mv.PairX, mv.PairY)
+	}
+	if mv.Mark > 0 {
+		coord += markSeparator + strconv.Itoa(mv.Mark)
+	}
+	return coord
+}
+
+// maskAt returns the mask of cell (x, y), open if the record has none.
+func (r *Record) maskAt(x, y int) game.CellMask {
+	if x >= len(r.Mask) || y >= len(r.Mask[x]) {
+		return game.CellOpen
+	}
+	return r.Mask[x][y]
+}
+
+// isMasked reports whether some cell of the record is not open.
+func (r *Record) isMasked() bool {
+	for x := 0; x < r.Width; x++ {
+		for y := 0; y < r.Height; y++ {
+			if r.maskAt(x, y) != game.CellOpen {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatMask encodes the mask of the record as rows of maskChars.
+func (r *Record) formatMask() string {
+	rows := make([]string, r.Height)
+	for y := range rows {
+		row := make([]byte, r.Width)
+		for x := range row {
+			row[x] = maskChars[r.maskAt(x, y)]
+		}
+		rows[y] = string(row)
+	}
+	return strings.Join(rows, maskRowSeparator)
+}
+
+// isStandard reports whether rules are the classic rules.
+func isStandard(rules game.Ruleset) bool {
+	return rules == nil || rules == game.Ruleset(game.StandardRules{})
+}
+
+// writeHeader appends a single header line, escaping the value.
+func writeHeader(sb *strings.Builder, key, value string) {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, `"`, `\"`)
+	fmt.Fprintf(sb, "[%s \"%s\"]\n", key, escaped)
+}
+
+// formatColor returns the color as #rrggbb, or #rrggbbaa when not opaque.
+func formatColor(c color.RGBA) string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// formatSchedule encodes a turn schedule as a comma-separated list.
+func formatSchedule(schedule game.TurnSchedule) string {
+	fields := make([]string, len(schedule))
+	for i, n := range schedule {
+		fields[i] = strconv.Itoa(n)
+	}
+	return strings.Join(fields, scheduleSeparator)
+}
+
+// toRGBA converts any color to its 8-bit RGBA form.
+func toRGBA(c color.Color) color.RGBA {
+	rgba, ok := color.RGBAModel.Convert(c).(color.RGBA)
+	if !ok {
+		return color.RGBA{}
+	}
+	return rgba
+}
@@ -23,7 +23,10 @@ package screens
 import (
 	"GoTicTacToe/ai_models"
 	"GoTicTacToe/assets"
+	"GoTicTacToe/game"
+	"fmt"
 	"image/color"
+	"time"
 )
 
 // Default game configuration values.
@@ -41,6 +44,254 @@ var (
 	defaultPlayer2Color = color.RGBA{R: 54, G: 162, B: 235, A: defaultColorAlpha}
 )
 
+// RuleVariant selects the rule set of a match.
+type RuleVariant int
+
+// Available rule sets, in the order they are cycled by the setup screen.
+const (
+	RulesClassic         RuleVariant = iota // Classic k-in-a-row
+	RulesGomokuFreestyle                    // Gomoku, five or more wins
+	RulesGomokuStandard                     // Gomoku, exactly five wins
+	RulesRenju                              // Gomoku with Renju restrictions
+	RulesUltimate                           // Ultimate Tic-Tac-Toe (3x3 grid of 3x3 boards)
+	RulesQubic                              // 3D 4x4x4 board (Qubic)
+	RulesPente                              // Pente, with custodial captures
+	RulesAchi                               // Achi: place 4 marks each, then slide them
+	RulesThreeMensMorris                    // Three Men's Morris: place 3 marks each, then slide them
+	RulesOrderChaos                         // Order and Chaos: either symbol may be placed
+	RulesNotakto                            // Notakto: everyone plays X, killing the last board loses
+	RulesQuantum                            // Quantum tic-tac-toe, with entangled spooky marks
+	RulesHex                                // k-in-a-row on a hexagon of hex cells
+	RulesUnbounded                          // Five in a row on a board without edges
+
+	ruleVariantCount // Number of rule variants
+)
+
+// ruleVariantNames holds the display name of each rule variant.
+var ruleVariantNames = map[RuleVariant]string{
+	RulesClassic:         "Classic",
+	RulesGomokuFreestyle: "Gomoku",
+	RulesGomokuStandard:  "Gomoku (exact)",
+	RulesRenju:           "Renju",
+	RulesUltimate:        "Ultimate",
+	RulesQubic:           "Qubic 3D",
+	RulesPente:           "Pente",
+	RulesAchi:            "Achi",
+	RulesThreeMensMorris: "3 Men's Morris",
+	RulesOrderChaos:      "Order & Chaos",
+	RulesNotakto:         "Notakto",
+	RulesQuantum:         "Quantum",
+	RulesHex:             "Hex",
+	RulesUnbounded:       "Infinite",
+}
+
+// String returns the display name of the rule variant.
+func (v RuleVariant) String() string {
+	if name, ok := ruleVariantNames[v]; ok {
+		return name
+	}
+	return ruleVariantNames[RulesClassic]
+}
+
+// Ruleset returns the game rules of the variant (nil for the classic rules).
+func (v RuleVariant) Ruleset() game.Ruleset {
+	switch v {
+	case RulesGomokuFreestyle:
+		return game.GomokuRules{Variant: game.GomokuFreestyle}
+	case RulesGomokuStandard:
+		return game.GomokuRules{Variant: game.GomokuStandard}
+	case RulesRenju:
+		return game.GomokuRules{Variant: game.GomokuRenju}
+	case RulesUltimate:
+		return game.UltimateRules{}
+	case RulesQubic:
+		return game.Rules3D{Depth: game.QubicSize}
+	case RulesPente:
+		return game.PenteRules{}
+	case RulesAchi:
+		return game.SlidingRules{Placements: game.AchiPlacements}
+	case RulesThreeMensMorris:
+		return game.SlidingRules{Placements: game.ThreeMensMorrisPlacements}
+	case RulesOrderChaos:
+		return game.OrderChaosRules{}
+	case RulesNotakto:
+		return game.NotaktoRules{}
+	case RulesQuantum:
+		return game.QuantumRules{}
+	case RulesHex:
+		return game.HexRules{}
+	case RulesUnbounded:
+		return game.UnboundedRules{}
+	default:
+		return nil
+	}
+}
+
+// FixedBoard reports whether the variant is played on a board of its own,
+// and returns its width, height and win condition if so. 3D boards are
+// stored flat, with their layers side by side.
+func (v RuleVariant) FixedBoard() (int, int, int, bool) {
+	switch v {
+	case RulesGomokuFreestyle, RulesGomokuStandard, RulesRenju:
+		return game.GomokuBoardSize, game.GomokuBoardSize, game.GomokuToWin, true
+	case RulesUltimate:
+		return game.UltimateBoardSize, game.UltimateBoardSize, game.UltimateSubSize, true
+	case RulesQubic:
+		return game.QubicSize * game.QubicSize, game.QubicSize, game.QubicToWin, true
+	case RulesPente:
+		return game.PenteBoardSize, game.PenteBoardSize, game.PenteToWin, true
+	case RulesAchi, RulesThreeMensMorris:
+		return game.SlidingBoardSize, game.SlidingBoardSize, game.SlidingBoardSize, true
+	case RulesOrderChaos:
+		return game.OrderChaosBoardSize, game.OrderChaosBoardSize, game.OrderChaosToWin, true
+	case RulesNotakto:
+		size := game.NotaktoBoardSize
+		return size * game.DefaultNotaktoBoards, size, size, true
+	case RulesQuantum:
+		return game.QuantumBoardSize, game.QuantumBoardSize, game.QuantumBoardSize, true
+	case RulesHex:
+		size := 2*game.HexRadius + 1
+		return size, size, game.HexToWin, true
+	case RulesUnbounded:
+		// The board has no edges: its size only carries the win condition
+		return game.UnboundedToWin, game.UnboundedToWin, game.UnboundedToWin, true
+	default:
+		return 0, 0, 0, false
+	}
+}
+
+// AllowsGravity reports whether the gravity option can be used with the variant.
+// Captures and slides would leave marks floating, so Pente and the sliding
+// variants do not allow it either, nor do Order and Chaos, Notakto,
+// quantum tic-tac-toe, Hex and the unbounded board, played on boards of
+// their own.
+func (v RuleVariant) AllowsGravity() bool {
+	switch v {
+	case RulesUltimate, RulesQubic, RulesPente, RulesAchi, RulesThreeMensMorris, RulesOrderChaos, RulesNotakto, RulesQuantum, RulesHex, RulesUnbounded:
+		return false
+	default:
+		return true
+	}
+}
+
+// AllowsWrap reports whether the wrap-around option can be used with the
+// variant. It is limited to the variants played with lines of the flat
+// board: sub-boards, layers, slides between adjacent cells, the small
+// quantum board and the hexagon stop at the edges, and the unbounded board
+// has none.
+func (v RuleVariant) AllowsWrap() bool {
+	switch v {
+	case RulesUltimate, RulesQubic, RulesAchi, RulesThreeMensMorris, RulesNotakto, RulesQuantum, RulesHex, RulesUnbounded:
+		return false
+	default:
+		return true
+	}
+}
+
+// AllowsMask reports whether the board shape and obstacle options can be
+// used with the variant. Boards made of sub-boards or layers, the sliding
+// variants, whose mark count fills the board, and quantum tic-tac-toe keep
+// every cell open, the hexagon of Hex has a shape of its own, and the
+// unbounded board has no cells to mask.
+func (v RuleVariant) AllowsMask() bool {
+	switch v {
+	case RulesUltimate, RulesQubic, RulesAchi, RulesThreeMensMorris, RulesNotakto, RulesQuantum, RulesHex, RulesUnbounded:
+		return false
+	default:
+		return true
+	}
+}
+
+// AllowsLineScoring reports whether the line scoring option can be used
+// with the variant. It needs plain lines of the flat board and a board that
+// fills up: Gomoku restrictions, captures, slides, sub-boards, layers,
+// choosing symbols, spooky marks and the other board geometries do not
+// allow it.
+func (v RuleVariant) AllowsLineScoring() bool {
+	return v == RulesClassic || v == RulesGomokuFreestyle
+}
+
+// ruleVariantOf returns the variant playing with the given rules.
+func ruleVariantOf(rules game.Ruleset) RuleVariant {
+	for v := RulesClassic; v < ruleVariantCount; v++ {
+		if v.Ruleset() == rules {
+			return v
+		}
+	}
+	return RulesClassic
+}
+
+// MatchFormat tells when a match ends: never (the zero value), after a
+// number of rounds or once a side reaches a number of points.
+type MatchFormat struct {
+	Format game.MatchFormat // Kind of match
+	Target int              // Rounds of a best-of match, points of a first-to match
+}
+
+// matchFormats lists the match formats, in the order they are cycled by
+// the setup screen.
+var matchFormats = []MatchFormat{
+	{Format: game.MatchOpen},
+	{Format: game.MatchBestOf, Target: 3},
+	{Format: game.MatchBestOf, Target: 5},
+	{Format: game.MatchFirstTo, Target: 3},
+	{Format: game.MatchFirstTo, Target: 5},
+}
+
+// String returns the display name of the match format.
+func (f MatchFormat) String() string {
+	switch f.Format {
+	case game.MatchBestOf:
+		return fmt.Sprintf("Best of %d", f.Target)
+	case game.MatchFirstTo:
+		return fmt.Sprintf("First to %d", f.Target)
+	default:
+		return "Open"
+	}
+}
+
+// startRotationNames holds the display name of each starting player rotation.
+var startRotationNames = map[game.StartRotation]string{
+	game.StartFixed:     "Same",
+	game.StartAlternate: "Alternate",
+	game.StartLoser:     "Loser",
+	game.StartWinner:    "Winner",
+}
+
+// timeControls lists the time controls offered by the setup, in the order
+// they are cycled, without their RandomOnFlag option.
+var timeControls = []game.TimeControl{
+	{Kind: game.TimeUnlimited},
+	{Kind: game.TimeSuddenDeath, Base: 3 * time.Minute},
+	{Kind: game.TimeFischer, Base: 3 * time.Minute, Bonus: 2 * time.Second},
+	{Kind: game.TimeBronstein, Base: 3 * time.Minute, Bonus: 5 * time.Second},
+	{Kind: game.TimePerMove, Base: 10 * time.Second},
+}
+
+// timeControlLabel returns the display name of a time control.
+func timeControlLabel(tc game.TimeControl) string {
+	switch tc.Kind {
+	case game.TimeSuddenDeath:
+		return durationLabel(tc.Base)
+	case game.TimeFischer:
+		return durationLabel(tc.Base) + " + " + durationLabel(tc.Bonus)
+	case game.TimeBronstein:
+		return durationLabel(tc.Base) + ", " + durationLabel(tc.Bonus) + " delay"
+	case game.TimePerMove:
+		return durationLabel(tc.Base) + " / move"
+	default:
+		return "Off"
+	}
+}
+
+// durationLabel returns a duration in whole minutes, or in seconds.
+func durationLabel(d time.Duration) string {
+	if d >= time.Minute && d%time.Minute == 0 {
+		return fmt.Sprintf("%d min", d/time.Minute)
+	}
+	return fmt.Sprintf("%d s", d/time.Second)
+}
+
 // PlayerConfig contains the customization options for one player slot.
 //
 // This structure is used by the UI to configure players before a match starts.
@@ -52,17 +303,32 @@ type PlayerConfig struct {
 	IsAI    bool              // Indicates whether the player is AI-controlled
 	AIModel ai_models.AIModel // AI strategy (used only if IsAI is true)
 	Ready   bool              // Indicates whether the player is ready to start
+	Team    int               // Team of the player (0 = playing alone)
 }
 
 // GameConfig aggregates the full setup required before launching a match.
 //
-// It defines the board dimensions, the win condition, and all participating
-// players.
+// It defines the board dimensions, the win condition, the rule options and
+// all participating players.
 type GameConfig struct {
-	BoardWidth  int            // Number of columns in the grid
-	BoardHeight int            // Number of rows in the grid
-	ToWin       int            // Number of aligned symbols required to win
-	Players     []PlayerConfig // Player configurations
+	BoardWidth  int                // Number of columns in the grid
+	BoardHeight int                // Number of rows in the grid
+	ToWin       int                // Number of aligned symbols required to win
+	Gravity     bool               // Marks fall to the lowest empty row (Connect Four)
+	Wrap        bool               // Lines continue across the edges (toroidal board)
+	TeamLines   bool               // Marks of teammates count together in lines
+	Misere      bool               // Completing a line loses the round
+	LineScoring bool               // Every line scores a point, the round going on until the board is full
+	MaxMarks    int                // Marks each player may hold, the oldest vanishing (0 = unlimited)
+	Schedule    game.TurnSchedule  // Marks placed in each turn (nil = one)
+	Rules       RuleVariant        // Rule set of the match
+	Boards      int                // Number of Notakto boards (0 = game.DefaultNotaktoBoards)
+	Shape       game.MaskShape     // Shape of the board, its other cells being void
+	Obstacles   int                // Number of random cells blocked at the start of the match
+	Match       MatchFormat        // When the match ends
+	Rotation    game.StartRotation // Who moves first in the rounds after the first
+	TimeControl game.TimeControl   // Time each player has to play
+	Players     []PlayerConfig     // Player configurations
 }
 
 // DefaultGameConfig returns a ready-to-play configuration.
@@ -90,3 +356,13 @@ func DefaultGameConfig() GameConfig {
 		},
 	}
 }
+
+// FixedBoard returns the board of the configured variant like
+// RuleVariant.FixedBoard, with the configured number of Notakto boards.
+func (cfg GameConfig) FixedBoard() (int, int, int, bool) {
+	w, h, k, ok := cfg.Rules.FixedBoard()
+	if cfg.Rules == RulesNotakto && cfg.Boards > 0 {
+		w = game.NotaktoBoardSize * cfg.Boards
+	}
+	return w, h, k, ok
+}
@@ -4,11 +4,16 @@ import (
 	"GoTicTacToe/ai_models"
 	"GoTicTacToe/assets"
 	"GoTicTacToe/game"
+	"GoTicTacToe/record"
 	"GoTicTacToe/ui"
 	uiutils "GoTicTacToe/ui/utils"
 	"fmt"
 	"image/color"
+	"log"
+	"math/rand"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -20,9 +25,16 @@ import (
 type GameScreen struct {
 	host      ScreenHost
 	game      *game.Game
-	boardView *ui.BoardView
+	match     *game.Match      // Rounds played on game and when they end
+	clock     *game.ChessClock // Players' clocks, stopped without a time control
+	board     ui.BoardWidget   // Board widget updated and drawn each frame
 	scoreView *ui.ScoreView
 	playerAI  map[*game.Player]ai_models.AIModel
+
+	ultimateView *ui.UltimateBoardView // Board widget in Ultimate Tic-Tac-Toe (nil otherwise)
+	quantumView  *ui.BoardView         // Board widget in quantum tic-tac-toe (nil otherwise)
+
+	source *game.Move // Mark selected to slide during a movement phase (nil if none)
 }
 
 const (
@@ -32,24 +44,41 @@ const (
 	// Board visual size in pixels.
 	boardPixelSize = 480.0
 
+	// Size in pixels of one layer of a 3D board, and vertical offset of the
+	// layers (leaving room for the layer labels).
+	layerPixelSize      = 240.0
+	layeredBoardOffsetY = -10.0
+
 	// Score view size in pixels.
 	scorePixelWidth  = 300
 	scorePixelHeight = 80
 
-	// Sentinel for "no move".
-	noMoveCoord = -1
-
 	// Number of frames a key must be held to trigger global action.
 	keyHoldFramesToTrigger = 60
 
 	// Opaque alpha channel value.
 	colorAlphaOpaque = 255
+
+	// Distance in pixels between the bottom of the screen and the symbol hint.
+	symbolHintOffsetY = 24.0
+
+	// Distance in pixels between the end message and the match score below it.
+	matchScoreOffsetY = 44.0
+
+	// Format of the board labels in Notakto, given the 1-based board number.
+	notaktoLabelFormat = "Board %d"
+
+	// Layout of the file name used when saving a match record.
+	recordFileTimeLayout = "20060102-150405"
 )
 
 var (
 	// Color used for the end-of-game message (yellow).
 	endMessageColor = color.RGBA{R: 255, G: 255, B: 0, A: colorAlphaOpaque}
 
+	// Color used for the symbol choice hint (light blue).
+	symbolHintColor = color.RGBA{R: 200, G: 220, B: 255, A: colorAlphaOpaque}
+
 	// Default colors used when player config does not define a color.
 	defaultPlayerColors = []color.Color{
 		color.RGBA{R: 255, G: 99, B: 132, A: colorAlphaOpaque},
@@ -61,6 +90,11 @@ var (
 
 // NewGameScreen initializes a new GameScreen with a fresh game and board view.
 func NewGameScreen(h ScreenHost, cfg GameConfig) *GameScreen {
+	// Some variants are always played on the same board
+	if w, h, k, ok := cfg.FixedBoard(); ok {
+		cfg.BoardWidth, cfg.BoardHeight, cfg.ToWin = w, h, k
+	}
+
 	boardWidth := cfg.BoardWidth
 	if boardWidth < minBoardDimension {
 		boardWidth = minBoardDimension
@@ -84,53 +118,152 @@ func NewGameScreen(h ScreenHost, cfg GameConfig) *GameScreen {
 
 	// Create game logic
 	g := game.NewGameWithConfig(boardWidth, boardHeight, toWin, players)
+	g.Board.Gravity = cfg.Gravity && cfg.Rules.AllowsGravity()
+	g.Board.Wrap = cfg.Wrap && cfg.Rules.AllowsWrap()
+	g.Board.TeamLines = cfg.TeamLines
+	if cfg.Rules.AllowsMask() {
+		g.Board.SetMask(cfg.Shape.Mask(boardWidth, boardHeight))
+		g.Board.BlockRandomCells(cfg.Obstacles)
+	}
+	g.Misere = cfg.Misere
+	g.LineScoring = cfg.LineScoring && cfg.Rules.AllowsLineScoring()
+	g.MaxMarks = cfg.MaxMarks
+	g.Schedule = cfg.Schedule
+	g.Rules = cfg.Rules.Ruleset()
+	if cfg.Rules == RulesHex {
+		g.Board.SetMask(game.HexMask(game.HexBoardOf(g.Board).Radius))
+	}
 
 	gs := &GameScreen{
 		host:     h,
 		game:     g,
+		match:    game.NewMatch(g, cfg.Match.Format, cfg.Match.Target, cfg.Rotation),
+		clock:    game.NewChessClock(g, cfg.TimeControl, game.SystemClock{}),
 		playerAI: aiMap,
 	}
 
 	gs.scoreView = ui.NewScoreView(g, scorePixelWidth, scorePixelHeight, uiutils.DefaultWidgetStyle)
+	if gs.clock.Enabled() {
+		gs.scoreView.Clock = gs.clock
+	}
 
 	// Create the interactive board view with callback on cell click
-	gs.boardView = ui.NewBoardView(
-		g.Board, // Logical board reference
-		0, 0,
-		boardPixelSize, // Pixel size
-		uiutils.DefaultWidgetStyle,
-		func(x, y int) {
-			gs.game.PlayMove(x, y)
-		},
-	)
+	onClick := func(x, y int) {
+		gs.clickCell(x, y)
+	}
+	switch rules := g.Rules.(type) {
+	case game.UltimateRules:
+		gs.ultimateView = ui.NewUltimateBoardView(
+			rules.Ultimate(g), // Composite view of the logical board
+			0, 0,
+			boardPixelSize, // Pixel size
+			uiutils.DefaultWidgetStyle,
+			onClick,
+		)
+		gs.board = gs.ultimateView
+	case game.NotaktoRules:
+		boards := rules.Boards(g)
+		view := ui.NewLayeredBoardView(
+			boards, // Boards of the logical board, side by side
+			0, layeredBoardOffsetY,
+			layerPixelSize, // Pixel size of a board
+			uiutils.DefaultWidgetStyle,
+			func(x, y, z int) {
+				gs.game.PlayMove(boards.Flatten(x, y, z))
+			},
+		)
+		view.LabelFormat = notaktoLabelFormat
+		view.SetSharedSymbol(assets.NewSymbol(assets.CrossSymbol))
+		gs.board = view
+	case game.HexRules:
+		gs.board = ui.NewHexBoardView(
+			rules.HexBoard(g), // Hex view of the logical board
+			0, 0,
+			boardPixelSize, // Pixel size
+			uiutils.DefaultWidgetStyle,
+			onClick,
+		)
+	case game.UnboundedRules:
+		gs.board = ui.NewSparseBoardView(
+			func() *game.SparseBoard { return rules.Sparse(g) }, // Unbounded board of the current round
+			0, 0,
+			boardPixelSize, // Pixel size
+			uiutils.DefaultWidgetStyle,
+			onClick,
+		)
+	case game.Rules3D:
+		b3 := rules.Board3D(g)
+		gs.board = ui.NewLayeredBoardView(
+			b3, // 3D view of the logical board
+			0, layeredBoardOffsetY,
+			layerPixelSize, // Pixel size of a layer
+			uiutils.DefaultWidgetStyle,
+			func(x, y, z int) {
+				gs.game.PlayMove(b3.Flatten(x, y, z))
+			},
+		)
+	default:
+		view := ui.NewBoardView(
+			g.Board, // Logical board reference
+			0, 0,
+			boardPixelSize, // Pixel size
+			uiutils.DefaultWidgetStyle,
+			onClick,
+		)
+		// A right click places the second symbol when players may choose it
+		if _, ok := g.Rules.(game.SymbolRules); ok {
+			view.OnCellAltClick = func(x, y int) {
+				gs.placeSymbol(x, y, 1)
+			}
+		}
+		if _, ok := g.Rules.(game.QuantumRules); ok {
+			gs.quantumView = view
+		}
+		gs.board = view
+	}
 
 	return gs
 }
 
 // Update processes input and updates UI components.
 func (gs *GameScreen) Update() error {
+	// Switch the clocks once the moves of this frame are played, so that
+	// the mover is not charged for the time until the next frame
+	defer gs.clock.Update()
+
+	// Run the clock of the current player, who loses or plays at random
+	// once out of time
+	if flagged := gs.clock.Update(); flagged != nil {
+		if gs.clock.Control.RandomOnFlag {
+			gs.playRandomLegalMove()
+		} else {
+			gs.game.TimeOut(flagged)
+		}
+	}
+
 	// Handle AI board interactions
 	if gs.game.State == game.StatePlaying {
 		current := gs.game.Current
 		if current.IsAI {
 			model := gs.playerAI[current]
 			if model != nil {
-				x, y := model.NextMove(gs.game.Board, current, gs.game.Players)
-				if x >= 0 && y >= 0 && x != noMoveCoord && y != noMoveCoord {
-					gs.game.PlayMove(x, y)
-				}
+				gs.playAITurn(model, current)
 			}
 			return nil // skip human input this frame
 		}
 	}
 
 	// Handle Human board interactions
-	gs.boardView.Update()
+	gs.board.Update()
 
-	// Reset the game if it's finished and the user clicks anywhere
+	// Start the next round if it's finished and the user clicks anywhere,
+	// or a new match once the match is over
 	if gs.game.State == game.StateGameEnd {
 		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			gs.game.Reset()
+			if !gs.match.NextRound() {
+				gs.match.Restart()
+			}
+			gs.clock.Reset()
 		}
 	}
 
@@ -139,18 +272,259 @@ func (gs *GameScreen) Update() error {
 		os.Exit(0)
 	}
 	if inpututil.KeyPressDuration(ebiten.KeyR) == keyHoldFramesToTrigger {
-		gs.game.Reset()
-		gs.game.ResetPoints()
+		gs.match.Restart()
+		gs.clock.Reset()
+	}
+	if inpututil.KeyPressDuration(ebiten.KeyS) == keyHoldFramesToTrigger {
+		gs.saveRecord()
+	}
+
+	// History navigation
+	if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		gs.undoTurn()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		gs.redoTurn()
 	}
 
 	return nil
 }
 
+// clickCell plays the cell clicked by a human player.
+//
+// During a movement phase, a first click selects one of the player's marks
+// and a click on another cell slides it there. Clicking another own mark
+// changes the selection; an illegal target keeps it.
+func (gs *GameScreen) clickCell(x, y int) {
+	if rules, ok := gs.game.Rules.(game.QuantumRules); ok && rules.NeedsPair(gs.game) {
+		gs.clickPair(x, y)
+		return
+	}
+	if !gs.game.Moving() {
+		gs.placeSymbol(x, y, 0)
+		return
+	}
+
+	if gs.game.Board.Cells[x][y] == gs.game.Current {
+		gs.source = &game.Move{X: x, Y: y}
+		return
+	}
+	if gs.source != nil && gs.game.Play(game.NewSlide(gs.source.X, gs.source.Y, x, y)) == nil {
+		gs.source = nil
+	}
+}
+
+// clickPair builds the spooky move of a human player in quantum
+// tic-tac-toe: a first click selects a cell free of classical marks and a
+// click on another cell plays both. Clicking the selected cell again
+// cancels the selection.
+func (gs *GameScreen) clickPair(x, y int) {
+	cell := game.Move{X: x, Y: y}
+	switch {
+	case gs.source == nil:
+		if gs.game.Board.Cells[x][y] == nil {
+			gs.source = &cell
+		}
+	case *gs.source == cell:
+		gs.source = nil
+	case gs.game.Play(game.NewSpookyMove(gs.source.X, gs.source.Y, x, y)) == nil:
+		gs.source = nil
+	}
+}
+
+// placeSymbol places at (x, y) the i-th symbol the current player may place
+// (see game.Game.Marks). It reports whether the move was played.
+func (gs *GameScreen) placeSymbol(x, y, i int) bool {
+	marks := gs.game.Marks()
+	if i < 0 || i >= len(marks) {
+		return false
+	}
+	return gs.game.Play(game.NewMove(x, y).WithMark(marks[i])) == nil
+}
+
+// highlight returns the cells to highlight: the winning line, the lines
+// scored so far with line scoring, and in Notakto the lines of the dead
+// boards.
+func (gs *GameScreen) highlight() []game.Move {
+	if gs.game.LineScoring {
+		var cells []game.Move
+		for _, line := range gs.game.Board.FindLines() {
+			cells = append(cells, line.Cells...)
+		}
+		return cells
+	}
+
+	rules, ok := gs.game.Rules.(game.NotaktoRules)
+	if !ok {
+		return gs.game.WinLine.Cells
+	}
+
+	var cells []game.Move
+	for _, line := range rules.DeadLines(gs.game) {
+		cells = append(cells, line.Cells...)
+	}
+	return cells
+}
+
+// selection returns the cells to show as selected: the mark about to
+// slide, if it is still a mark of the player to move. In quantum
+// tic-tac-toe, these are the first cell of the spooky move being built or
+// the two cells a pending collapse may choose from.
+func (gs *GameScreen) selection() []game.Move {
+	if rules, ok := gs.game.Rules.(game.QuantumRules); ok {
+		if pending := rules.State(gs.game).Pending; pending != nil && gs.game.IsPlaying() {
+			return pending.Cells[:]
+		}
+		if gs.source == nil || !rules.NeedsPair(gs.game) || gs.game.Board.Cells[gs.source.X][gs.source.Y] != nil {
+			return nil
+		}
+		return []game.Move{*gs.source}
+	}
+
+	if gs.source == nil || !gs.game.Moving() || gs.game.Board.Cells[gs.source.X][gs.source.Y] != gs.game.Current {
+		return nil
+	}
+	return []game.Move{*gs.source}
+}
+
+// playAITurn plays the moves chosen by model for the current turn of player.
+// Models planning a single placement are asked again on the next frames
+// until the turn is over.
+func (gs *GameScreen) playAITurn(model ai_models.AIModel, player *game.Player) {
+	for _, m := range ai_models.NextTurn(model, gs.game, player) {
+		if gs.game.Current != player || !gs.game.IsPlaying() {
+			return
+		}
+		if err := gs.game.Play(m); err != nil {
+			gs.playRandomLegalMove()
+			return
+		}
+	}
+}
+
+// playRandomLegalMove plays a random legal move for the current player.
+// It is used when an AI model picks a move the rules refuse, e.g. a
+// forbidden Renju move, so that the match never gets stuck.
+func (gs *GameScreen) playRandomLegalMove() {
+	moves := gs.game.LegalMoves()
+	if len(moves) == 0 {
+		return
+	}
+	if err := gs.game.Play(moves[rand.Intn(len(moves))]); err != nil {
+		log.Printf("playing fallback move: %v", err)
+	}
+}
+
+// undoTurn takes back moves until a human player's turn starts again,
+// so that AI replies are undone together with the human move they answered
+// and multi-placement turns are undone as a whole.
+func (gs *GameScreen) undoTurn() {
+	for gs.game.Undo() {
+		if !gs.game.Current.IsAI && gs.isTurnStart() {
+			return
+		}
+	}
+}
+
+// redoTurn replays undone moves until a human player's turn starts again.
+func (gs *GameScreen) redoTurn() {
+	for gs.game.Redo() {
+		if (!gs.game.Current.IsAI && gs.isTurnStart()) || gs.game.State != game.StatePlaying {
+			return
+		}
+	}
+}
+
+// isTurnStart reports whether no mark has been placed yet in the current turn.
+func (gs *GameScreen) isTurnStart() bool {
+	return gs.game.PlacementsLeft() == gs.game.TurnPlacements()
+}
+
+// NewGameScreenFromRecord creates a GameScreen for a saved match and
+// replays its moves, so play resumes where the record stops.
+func NewGameScreenFromRecord(h ScreenHost, rec *record.Record) (*GameScreen, error) {
+	cfg := GameConfig{
+		BoardWidth:  rec.Width,
+		BoardHeight: rec.Height,
+		ToWin:       rec.ToWin,
+		Gravity:     rec.Gravity,
+		Wrap:        rec.Wrap,
+		TeamLines:   rec.TeamLines,
+		Misere:      rec.Misere,
+		LineScoring: rec.LineScoring,
+		MaxMarks:    rec.MaxMarks,
+		Schedule:    rec.Schedule,
+		Rules:       ruleVariantOf(rec.Rules),
+	}
+	if cfg.Rules == RulesNotakto {
+		cfg.Boards = rec.Width / game.NotaktoBoardSize
+	}
+	for _, info := range rec.Players {
+		pc := PlayerConfig{
+			Name:   info.Name,
+			Color:  info.Color,
+			Symbol: info.Symbol,
+			Team:   info.Team,
+			Ready:  true,
+		}
+		if info.AI != "" {
+			model, ok := ai_models.ModelByName(info.AI)
+			if !ok {
+				return nil, fmt.Errorf("unknown AI model %q for %s", info.AI, info.Name)
+			}
+			pc.IsAI = true
+			pc.AIModel = model
+		}
+		cfg.Players = append(cfg.Players, pc)
+	}
+
+	gs := NewGameScreen(h, cfg)
+	gs.game.Board.SetMask(rec.Mask)
+	if rec.FirstPlayer > 0 {
+		gs.game.SetFirstPlayer(gs.game.Players[rec.FirstPlayer-1])
+	}
+	if err := rec.Apply(gs.game); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+// saveRecord writes the current match to a record file in the working directory.
+func (gs *GameScreen) saveRecord() {
+	rec := record.New(gs.game)
+	for i, p := range gs.game.Players {
+		rec.Players[i].AI = ai_models.ModelName(gs.playerAI[p])
+	}
+
+	path := "match-" + time.Now().Format(recordFileTimeLayout) + record.FileExtension
+	if err := rec.WriteFile(path); err != nil {
+		log.Printf("saving match record: %v", err)
+		return
+	}
+	log.Printf("match record saved to %s", path)
+}
+
 // Draw renders the board and HUD.
 func (gs *GameScreen) Draw(screen *ebiten.Image) {
-	// Draw board component
-	gs.boardView.Draw(screen)
+	// Draw board component, highlighting the winning line if any
+	gs.board.SetHighlight(gs.highlight())
+	gs.board.SetSelection(gs.selection())
+	gs.board.SetFading(nil)
+	if m, ok := gs.game.VanishingMark(); ok {
+		gs.board.SetFading([]game.Move{m})
+	}
+	if gs.quantumView != nil {
+		gs.quantumView.Spooky = gs.spookyMarks()
+	}
+	if gs.ultimateView != nil {
+		gs.ultimateView.Active = nil
+		if rules, ok := gs.game.Rules.(game.UltimateRules); ok && gs.game.IsPlaying() {
+			gs.ultimateView.Active = rules.ActiveSubBoards(gs.game)
+		}
+	}
+	gs.board.Draw(screen)
 	gs.scoreView.Draw(screen)
+	gs.drawSymbolHint(screen)
 
 	// Display win/draw message if needed
 	if gs.game.State == game.StateGameEnd {
@@ -158,12 +532,63 @@ func (gs *GameScreen) Draw(screen *ebiten.Image) {
 	}
 }
 
-// drawEndMessage displays a centered win/draw message at the end of a game.
+// spookyMarks returns the spooky marks of quantum tic-tac-toe by cell.
+func (gs *GameScreen) spookyMarks() map[game.Move][]game.SpookyMark {
+	rules, ok := gs.game.Rules.(game.QuantumRules)
+	if !ok {
+		return nil
+	}
+
+	state := rules.State(gs.game)
+	marks := map[game.Move][]game.SpookyMark{}
+	for _, mark := range state.Spooky {
+		for _, cell := range mark.Cells {
+			marks[cell] = append(marks[cell], mark)
+		}
+	}
+	return marks
+}
+
+// drawSymbolHint tells a human player which mouse button places which
+// symbol, when the rules let them choose (Order and Chaos).
+func (gs *GameScreen) drawSymbolHint(screen *ebiten.Image) {
+	marks := gs.game.Marks()
+	if len(marks) < 2 || gs.game.Current.IsAI || !gs.game.IsPlaying() {
+		return
+	}
+
+	opts := &text.DrawOptions{}
+	opts.PrimaryAlign = text.AlignCenter
+	opts.SecondaryAlign = text.AlignCenter
+
+	sw, sh := screen.Bounds().Dx(), screen.Bounds().Dy()
+	opts.GeoM.Translate(float64(sw)/2, float64(sh)-symbolHintOffsetY)
+
+	opts.ColorScale.ScaleWithColor(symbolHintColor)
+	msg := fmt.Sprintf("Left click: %s's symbol   Right click: %s's symbol", marks[0].Name, marks[1].Name)
+	text.Draw(screen, msg, assets.NormalFont, opts)
+}
+
+// drawEndMessage displays a centered win/draw message at the end of a game,
+// the result of the match once it is over, and the match score below.
 func (gs *GameScreen) drawEndMessage(screen *ebiten.Image) {
 	var msg string
-	if gs.game.Winner != nil {
+	switch winner := gs.match.Winner(); {
+	case gs.match.Over() && winner == nil:
+		msg = "The match is a draw!"
+	case gs.match.Over() && winner.Team > 0:
+		msg = fmt.Sprintf("Team %d wins the match!", winner.Team)
+	case gs.match.Over():
+		msg = fmt.Sprintf("%s wins the match!", winner.Name)
+	case gs.game.TimedOut() != nil:
+		msg = fmt.Sprintf("%s ran out of time!", gs.game.TimedOut().Name)
+	case gs.game.Winner != nil && gs.game.Winner.Team > 0:
+		msg = fmt.Sprintf("Team %d wins!", gs.game.Winner.Team)
+	case gs.game.Winner != nil:
 		msg = fmt.Sprintf("%s wins!", gs.game.Winner.Name)
-	} else {
+	case gs.game.Loser != nil:
+		msg = fmt.Sprintf("%s loses!", gs.game.Loser.Name)
+	default:
 		msg = "It's a draw!"
 	}
 
@@ -176,10 +601,31 @@ func (gs *GameScreen) drawEndMessage(screen *ebiten.Image) {
 
 	opts.ColorScale.ScaleWithColor(endMessageColor)
 	text.Draw(screen, msg, assets.BigFont, opts)
+
+	if gs.match.Format == game.MatchOpen {
+		return
+	}
+	score := "Match score: "
+	if gs.match.Over() {
+		score = "Final score: "
+	}
+	for i, side := range gs.match.Sides() {
+		if i > 0 {
+			score += " - "
+		}
+		score += strconv.Itoa(gs.match.Points(side))
+	}
+
+	scoreOpts := &text.DrawOptions{}
+	scoreOpts.PrimaryAlign = text.AlignCenter
+	scoreOpts.SecondaryAlign = text.AlignCenter
+	scoreOpts.GeoM.Translate(float64(sw)/2, float64(sh)/2+matchScoreOffsetY)
+	scoreOpts.ColorScale.ScaleWithColor(endMessageColor)
+	text.Draw(screen, score, assets.NormalFont, scoreOpts)
 }
 
-// buildPlayers turns the setup configuration into runtime players
-// and returns a map of AI models keyed by player for quick lookup.
+// buildPlayers turns the setup configuration into runtime players, in turn
+// order, and returns a map of AI models keyed by player for quick lookup.
 func buildPlayers(cfg GameConfig) ([]*game.Player, map[*game.Player]ai_models.AIModel) {
 	var players []*game.Player
 	aiByPlayer := map[*game.Player]ai_models.AIModel{}
@@ -211,6 +657,7 @@ func buildPlayers(cfg GameConfig) ([]*game.Player, map[*game.Player]ai_models.AI
 			p.Name = fmt.Sprintf("Player %d", idx+1)
 		}
 		p.IsAI = pc.IsAI
+		p.Team = pc.Team
 
 		players = append(players, p)
 
@@ -233,5 +680,6 @@ func buildPlayers(cfg GameConfig) ([]*game.Player, map[*game.Player]ai_models.AI
 		}
 	}
 
-	return players, aiByPlayer
+	// Teams take turns alternately
+	return game.TeamOrder(players), aiByPlayer
 }
@@ -3,10 +3,14 @@ package screens
 import (
 	"GoTicTacToe/ai_models"
 	"GoTicTacToe/assets"
+	"GoTicTacToe/game"
 	"GoTicTacToe/ui"
 	uiutils "GoTicTacToe/ui/utils"
 	"fmt"
 	"image/color"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -20,6 +24,13 @@ type playerCardButtons struct {
 	ready      *ui.Button // Button to toggle the player's ready state
 }
 
+// optionToggle is a setup button cycling a rule option, with the function
+// computing its label from the current configuration.
+type optionToggle struct {
+	button *ui.Button    // Button shown in the options rows
+	label  func() string // Returns the label reflecting the current value
+}
+
 // SetupScreen lets the user configure players and board size before starting.
 // It provides controls for grid dimensions, win condition, and player configuration.
 type SetupScreen struct {
@@ -28,6 +39,7 @@ type SetupScreen struct {
 	buttons       []*ui.Button         // All interactive buttons on the screen
 	playerCards   []*ui.PlayerCardView // Visual cards displaying player info
 	playerButtons []playerCardButtons  // Button groups for each player
+	options       []optionToggle       // Rule option buttons (rules, gravity, misère, ...)
 	addPlayerBtn  *ui.Button           // Button to add a new player
 	startBtn      *ui.Button           // Button to start the game
 	root          *ui.Container        // Root UI container for layout
@@ -45,6 +57,16 @@ const (
 	cardsPerRow  = 4     // Number of player cards per row
 )
 
+// Layout constants for rule option buttons.
+const (
+	optionWidth    = 180.0  // Width of an option button
+	optionHeight   = 36.0   // Height of an option button
+	optionSpacingX = 195.0  // Horizontal distance between option button centers
+	optionRowY     = -172.0 // Vertical offset from center of the first options row
+	optionRowStep  = 40.0   // Vertical distance between options rows
+	optionsPerRow  = 6      // Number of option buttons per row
+)
+
 // Grid size constraints.
 const (
 	minGridSize = 3 // Minimum grid dimension
@@ -52,6 +74,45 @@ const (
 	minToWin    = 3 // Minimum symbols needed to win
 )
 
+// Mark limit choices (infinite mode): 0 means unlimited.
+const (
+	minMarkLimit = 3 // Smallest mark limit offered
+	maxMarkLimit = 5 // Largest mark limit offered
+)
+
+// maxNotaktoBoards is the largest number of Notakto boards offered.
+const maxNotaktoBoards = 4
+
+// maxObstacles is the largest number of random obstacles offered.
+const maxObstacles = 6
+
+// maskShapeNames holds the display name of each board shape.
+var maskShapeNames = map[game.MaskShape]string{
+	game.ShapeFull:    "Full",
+	game.ShapePlus:    "Plus",
+	game.ShapeDiamond: "Diamond",
+	game.ShapeHoled:   "Holed",
+}
+
+// turnSchedules lists the turn schedules offered by the setup, by label.
+var turnSchedules = []struct {
+	label    string
+	schedule game.TurnSchedule
+}{
+	{label: "1 mark", schedule: nil},
+	{label: "Connect6", schedule: game.Connect6Schedule},
+	{label: "2 marks", schedule: game.TurnSchedule{2}},
+}
+
+// teamSplits lists the team assignments offered by the setup: no teams,
+// players alternating between two teams, or the first half of the players
+// against the second. team returns the team of player i out of n.
+var teamSplits = []func(i, n int) int{
+	func(int, int) int { return 0 },
+	func(i, _ int) int { return i%2 + 1 },
+	func(i, n int) int { return 2*i/n + 1 },
+}
+
 // playerPalette defines the available colors for players.
 var playerPalette = []color.RGBA{
 	{R: 255, G: 99, B: 132, A: 255},  // Pink/Red
@@ -133,6 +194,9 @@ func (s *SetupScreen) init() {
 	// Grid configuration controls (positioned below title)
 	s.buildGridControls()
 
+	// Rule options (below grid controls)
+	s.buildRuleControls()
+
 	// Player cards and their associated buttons
 	s.buildPlayerCards()
 
@@ -197,6 +261,300 @@ func (s *SetupScreen) buildGridControls() {
 	)
 }
 
+// buildRuleControls creates the toggle buttons for rule options.
+func (s *SetupScreen) buildRuleControls() {
+	s.options = nil
+
+	s.addOption(
+		func() string { return "Rules: " + s.config.Rules.String() },
+		func() { s.cycleRules() },
+	)
+	s.addOption(
+		func() string {
+			return "Gravity: " + onOffLabel(s.config.Gravity && s.config.Rules.AllowsGravity())
+		},
+		func() { s.config.Gravity = !s.config.Gravity },
+	)
+	s.addOption(
+		func() string {
+			return "Wrap: " + onOffLabel(s.config.Wrap && s.config.Rules.AllowsWrap())
+		},
+		func() { s.config.Wrap = !s.config.Wrap },
+	)
+	s.addOption(
+		func() string { return "Misère: " + onOffLabel(s.config.Misere) },
+		func() { s.config.Misere = !s.config.Misere },
+	)
+	s.addOption(
+		func() string {
+			return "Scoring: " + scoringLabel(s.config.LineScoring && s.config.Rules.AllowsLineScoring())
+		},
+		func() { s.config.LineScoring = !s.config.LineScoring },
+	)
+	s.addOption(
+		func() string { return "Max marks: " + markLimitLabel(s.config.MaxMarks) },
+		func() { s.cycleMarkLimit() },
+	)
+	s.addOption(
+		func() string { return "Turns: " + turnSchedules[s.turnScheduleIndex()].label },
+		func() { s.cycleTurnSchedule() },
+	)
+	s.addOption(
+		func() string { return "Notakto boards: " + strconv.Itoa(s.notaktoBoards()) },
+		func() { s.cycleNotaktoBoards() },
+	)
+	s.addOption(
+		func() string { return "Shape: " + maskShapeNames[s.maskShape()] },
+		func() { s.config.Shape = (s.maskShape() + 1) % game.MaskShapeCount },
+	)
+	s.addOption(
+		func() string { return "Obstacles: " + strconv.Itoa(s.obstacles()) },
+		func() { s.config.Obstacles = (s.config.Obstacles + 1) % (maxObstacles + 1) },
+	)
+	s.addOption(
+		func() string { return "Teams: " + s.teamsLabel() },
+		func() { s.cycleTeams() },
+	)
+	s.addOption(
+		func() string { return "Team lines: " + onOffLabel(s.config.TeamLines && s.teamSplit() > 0) },
+		func() { s.config.TeamLines = !s.config.TeamLines },
+	)
+	s.addOption(
+		func() string { return "Match: " + s.config.Match.String() },
+		func() { s.cycleMatchFormat() },
+	)
+	s.addOption(
+		func() string { return "First move: " + startRotationNames[s.config.Rotation] },
+		func() { s.config.Rotation = (s.config.Rotation + 1) % game.StartRotationCount },
+	)
+	s.addOption(
+		func() string { return "Clock: " + timeControlLabel(s.config.TimeControl) },
+		func() { s.cycleTimeControl() },
+	)
+	s.addOption(
+		func() string { return "Time out: " + timeOutLabel(s.config.TimeControl.RandomOnFlag) },
+		func() { s.config.TimeControl.RandomOnFlag = !s.config.TimeControl.RandomOnFlag },
+	)
+
+	s.layoutOptions()
+}
+
+// cycleRules switches to the next rule variant, applying its board
+// when it has a fixed one and bringing the board back within the
+// setup limits otherwise.
+func (s *SetupScreen) cycleRules() {
+	s.config.Rules = (s.config.Rules + 1) % ruleVariantCount
+
+	if w, h, k, ok := s.config.FixedBoard(); ok {
+		s.config.BoardWidth, s.config.BoardHeight, s.config.ToWin = w, h, k
+		return
+	}
+	s.changeGridWidth(0)
+	s.changeGridHeight(0)
+}
+
+// cycleMarkLimit switches the mark limit to the next choice:
+// Off, then minMarkLimit to maxMarkLimit.
+func (s *SetupScreen) cycleMarkLimit() {
+	switch {
+	case s.config.MaxMarks <= 0:
+		s.config.MaxMarks = minMarkLimit
+	case s.config.MaxMarks >= maxMarkLimit:
+		s.config.MaxMarks = 0
+	default:
+		s.config.MaxMarks++
+	}
+}
+
+// notaktoBoards returns the configured number of Notakto boards.
+func (s *SetupScreen) notaktoBoards() int {
+	if s.config.Boards <= 0 {
+		return game.DefaultNotaktoBoards
+	}
+	return s.config.Boards
+}
+
+// cycleNotaktoBoards switches the number of Notakto boards to the next
+// choice, from 1 to maxNotaktoBoards, and resizes the board if Notakto is
+// selected.
+func (s *SetupScreen) cycleNotaktoBoards() {
+	s.config.Boards = s.notaktoBoards()%maxNotaktoBoards + 1
+
+	if w, h, k, ok := s.config.FixedBoard(); ok {
+		s.config.BoardWidth, s.config.BoardHeight, s.config.ToWin = w, h, k
+	}
+}
+
+// maskShape returns the board shape in effect, always ShapeFull for
+// variants that do not allow masks.
+func (s *SetupScreen) maskShape() game.MaskShape {
+	if !s.config.Rules.AllowsMask() {
+		return game.ShapeFull
+	}
+	return s.config.Shape
+}
+
+// obstacles returns the number of random obstacles in effect, always 0
+// for variants that do not allow masks.
+func (s *SetupScreen) obstacles() int {
+	if !s.config.Rules.AllowsMask() {
+		return 0
+	}
+	return s.config.Obstacles
+}
+
+// teamSplit returns the index in teamSplits of the team assignment of the
+// players, 0 (no teams) if it is not one of them.
+func (s *SetupScreen) teamSplit() int {
+	n := len(s.config.Players)
+	for i, team := range teamSplits {
+		matches := true
+		for idx, pc := range s.config.Players {
+			matches = matches && pc.Team == team(idx, n)
+		}
+		if matches {
+			return i
+		}
+	}
+	return 0
+}
+
+// applyTeamSplit assigns the players to their teams following teamSplits[i].
+func (s *SetupScreen) applyTeamSplit(i int) {
+	n := len(s.config.Players)
+	for idx := range s.config.Players {
+		s.config.Players[idx].Team = teamSplits[i](idx, n)
+	}
+}
+
+// cycleTeams switches to the next team assignment, skipping the ones that
+// give the same teams as another with the current number of players.
+func (s *SetupScreen) cycleTeams() {
+	for next := s.teamSplit() + 1; ; next++ {
+		next %= len(teamSplits)
+		s.applyTeamSplit(next)
+		if next == 0 || s.teamSplit() == next {
+			return
+		}
+	}
+}
+
+// teamsLabel returns the label of the team assignment, listing the players
+// of each team, for example "1+3 / 2+4".
+func (s *SetupScreen) teamsLabel() string {
+	if s.teamSplit() == 0 {
+		return "Off"
+	}
+
+	var teams []string
+	for idx, pc := range s.config.Players {
+		for len(teams) < pc.Team {
+			teams = append(teams, "")
+		}
+		if teams[pc.Team-1] != "" {
+			teams[pc.Team-1] += "+"
+		}
+		teams[pc.Team-1] += strconv.Itoa(idx + 1)
+	}
+	return strings.Join(teams, " / ")
+}
+
+// cycleMatchFormat switches to the next match format.
+func (s *SetupScreen) cycleMatchFormat() {
+	i := slices.Index(matchFormats, s.config.Match)
+	s.config.Match = matchFormats[(i+1)%len(matchFormats)]
+}
+
+// cycleTimeControl switches to the next time control of timeControls,
+// keeping the time out option.
+func (s *SetupScreen) cycleTimeControl() {
+	current := s.config.TimeControl
+	current.RandomOnFlag = false
+
+	next := timeControls[(slices.Index(timeControls, current)+1)%len(timeControls)]
+	next.RandomOnFlag = s.config.TimeControl.RandomOnFlag
+	s.config.TimeControl = next
+}
+
+// turnScheduleIndex returns the index of the configured schedule in
+// turnSchedules, 0 (one mark per turn) if it is not one of them.
+func (s *SetupScreen) turnScheduleIndex() int {
+	for i, ts := range turnSchedules {
+		if slices.Equal(ts.schedule, s.config.Schedule) {
+			return i
+		}
+	}
+	return 0
+}
+
+// cycleTurnSchedule switches to the next turn schedule of turnSchedules.
+func (s *SetupScreen) cycleTurnSchedule() {
+	next := (s.turnScheduleIndex() + 1) % len(turnSchedules)
+	s.config.Schedule = turnSchedules[next].schedule
+}
+
+// addOption appends an option button whose label is computed by label and
+// which calls toggle (then refreshes labels) when clicked.
+func (s *SetupScreen) addOption(label func() string, toggle func()) {
+	btn := ui.NewButton(label(), 0, optionRowY, uiutils.AnchorCenter,
+		optionWidth, optionHeight, buttonRadius, uiutils.DefaultWidgetStyle,
+		func() {
+			toggle()
+			s.refreshLabels()
+		})
+
+	s.options = append(s.options, optionToggle{button: btn, label: label})
+	s.buttons = append(s.buttons, btn)
+}
+
+// layoutOptions centers the option buttons in rows of optionsPerRow.
+func (s *SetupScreen) layoutOptions() {
+	for i, opt := range s.options {
+		row := i / optionsPerRow
+		col := i % optionsPerRow
+
+		inRow := len(s.options) - row*optionsPerRow
+		if inRow > optionsPerRow {
+			inRow = optionsPerRow
+		}
+
+		opt.button.OffsetX = (float64(col) - float64(inRow-1)/2) * optionSpacingX
+		opt.button.OffsetY = optionRowY + float64(row)*optionRowStep
+	}
+}
+
+// onOffLabel returns "On" or "Off" for a boolean option.
+func onOffLabel(on bool) string {
+	if on {
+		return "On"
+	}
+	return "Off"
+}
+
+// scoringLabel returns the label of the scoring mode.
+func scoringLabel(lines bool) string {
+	if lines {
+		return "Lines"
+	}
+	return "First line"
+}
+
+// timeOutLabel returns what happens to a player out of time.
+func timeOutLabel(randomMove bool) string {
+	if randomMove {
+		return "Random move"
+	}
+	return "Loss"
+}
+
+// markLimitLabel returns the label of a mark limit, "Off" if unlimited.
+func markLimitLabel(limit int) string {
+	if limit <= 0 {
+		return "Off"
+	}
+	return strconv.Itoa(limit)
+}
+
 // buildPlayerCards creates the player cards and their associated control buttons.
 func (s *SetupScreen) buildPlayerCards() {
 	for i := range s.config.Players {
@@ -333,7 +691,11 @@ func (s *SetupScreen) drawGridInfo(screen *ebiten.Image, screenWidth int) {
 }
 
 // changeGridWidth adjusts the grid width by delta, clamping to valid bounds.
+// Grid controls have no effect while the rule variant fixes the board.
 func (s *SetupScreen) changeGridWidth(delta int) {
+	if _, _, _, fixed := s.config.Rules.FixedBoard(); fixed {
+		return
+	}
 	newWidth := s.config.BoardWidth + delta
 	if newWidth < minGridSize {
 		newWidth = minGridSize
@@ -349,6 +711,9 @@ func (s *SetupScreen) changeGridWidth(delta int) {
 
 // changeGridHeight adjusts the grid height by delta, clamping to valid bounds.
 func (s *SetupScreen) changeGridHeight(delta int) {
+	if _, _, _, fixed := s.config.Rules.FixedBoard(); fixed {
+		return
+	}
 	newHeight := s.config.BoardHeight + delta
 	if newHeight < minGridSize {
 		newHeight = minGridSize
@@ -364,6 +729,9 @@ func (s *SetupScreen) changeGridHeight(delta int) {
 
 // changeToWin adjusts the win condition by delta, clamping to valid bounds.
 func (s *SetupScreen) changeToWin(delta int) {
+	if _, _, _, fixed := s.config.Rules.FixedBoard(); fixed {
+		return
+	}
 	s.config.ToWin = clampToWin(s.config.ToWin+delta, s.config.BoardWidth, s.config.BoardHeight)
 }
 
@@ -426,7 +794,9 @@ func (s *SetupScreen) removePlayer(idx int) {
 		return
 	}
 
+	split := s.teamSplit()
 	s.config.Players = append(s.config.Players[:idx], s.config.Players[idx+1:]...)
+	s.applyTeamSplit(split)
 	s.init()
 	s.refreshLabels()
 }
@@ -492,11 +862,13 @@ func (s *SetupScreen) addPlayer() {
 	}
 
 	idx := len(s.config.Players)
+	split := s.teamSplit()
 	s.config.Players = append(s.config.Players, PlayerConfig{
 		Name:   fmt.Sprintf("Player %d", idx+1),
 		Color:  playerPalette[idx%len(playerPalette)],
 		Symbol: playerSymbolOrder[idx%len(playerSymbolOrder)],
 	})
+	s.applyTeamSplit(split)
 
 	s.init()
 	s.refreshLabels()
@@ -526,7 +898,7 @@ func (s *SetupScreen) refreshLabels() {
 		if i < len(s.playerCards) && s.playerCards[i] != nil {
 			s.playerCards[i].UpdateFromConfig(ui.PlayerCardConfig{
 				Name:     playerName,
-				Subtitle: s.roleLabel(pc),
+				Subtitle: s.cardSubtitle(pc),
 				Symbol:   pc.Symbol,
 				Color:    playerColor,
 				Ready:    pc.Ready,
@@ -539,6 +911,11 @@ func (s *SetupScreen) refreshLabels() {
 		}
 	}
 
+	// Update rule option labels
+	for _, opt := range s.options {
+		opt.button.Label = opt.label()
+	}
+
 	// Update add player button label
 	if s.addPlayerBtn != nil {
 		s.addPlayerBtn.Label = fmt.Sprintf("+ Add Player (%d/%d)", len(s.config.Players), maxPlayers)
@@ -567,6 +944,15 @@ func (s *SetupScreen) roleLabel(pc PlayerConfig) string {
 	return "Human"
 }
 
+// cardSubtitle returns the subtitle of a player card: the player's role,
+// followed by their team if they play in one.
+func (s *SetupScreen) cardSubtitle(pc PlayerConfig) string {
+	if pc.Team > 0 {
+		return fmt.Sprintf("%s, Team %d", s.roleLabel(pc), pc.Team)
+	}
+	return s.roleLabel(pc)
+}
+
 // canStartGame returns true if all conditions are met to start a game:
 // - At least 2 players
 // - All players are ready
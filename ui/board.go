@@ -27,11 +27,17 @@
 package ui
 
 import (
+	"GoTicTacToe/assets"
 	"GoTicTacToe/game"
 	"GoTicTacToe/ui/utils"
+	"image"
+	"image/color"
+	"slices"
+	"strconv"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 // Layout constants used by BoardView.
@@ -47,16 +53,63 @@ const (
 	two = 2.0
 )
 
+// Translucent fills drawn behind highlighted, selected and blocked cells.
+var (
+	highlightColor = color.RGBA{R: 255, G: 255, B: 0, A: 60}
+	selectionColor = color.RGBA{R: 80, G: 200, B: 255, A: 80}
+	blockedColor   = color.RGBA{R: 40, G: 40, B: 40, A: 220}
+)
+
+// fadingSymbolAlpha is the opacity of the symbols drawn in fading cells.
+const fadingSymbolAlpha = 0.35
+
+// spookyGridSize is the number of spooky marks drawn per row (and the
+// number of rows) in a cell of quantum tic-tac-toe.
+const spookyGridSize = 3
+
+// BoardWidget is implemented by every board view, whatever the geometry
+// of the board it renders.
+type BoardWidget interface {
+	Element
+
+	// SetHighlight highlights the given cells (e.g. the winning line),
+	// in game board coordinates.
+	SetHighlight(cells []game.Move)
+
+	// SetFading draws the symbols of the given cells faded (e.g. the mark
+	// about to vanish), in game board coordinates.
+	SetFading(cells []game.Move)
+
+	// SetSelection marks the given cells as selected (e.g. the source of
+	// a slide), in game board coordinates.
+	SetSelection(cells []game.Move)
+}
+
 // BoardView is the visual component responsible for rendering the
 // Tic-Tac-Toe board and handling user interaction.
 type BoardView struct {
 	Widget // Embeds Widget: inherits size, position, anchor, LayoutRect(), etc.
 
-	logicBoard  *game.Board      // Reference to the logical board
-	OnCellClick func(cx, cy int) // Callback triggered when a cell is clicked
+	logicBoard     *game.Board      // Reference to the logical board
+	OnCellClick    func(cx, cy int) // Callback triggered when a cell is clicked
+	OnCellAltClick func(cx, cy int) // Callback triggered when a cell is right-clicked (nil = ignored)
+	Highlight      []game.Move      // Cells drawn highlighted (e.g. the winning line)
+	Fading         []game.Move      // Cells whose symbol is drawn faded (e.g. the mark about to vanish)
+	Selection      []game.Move      // Cells drawn selected (e.g. the mark about to slide)
 
-	lastGridW int // Cached grid image width
-	lastGridH int // Cached grid image height
+	// SharedSymbol, if set, is drawn for every mark instead of the symbol of
+	// the player who placed it, e.g. in Notakto where everyone plays X.
+	SharedSymbol *assets.Symbol
+
+	// Spooky holds the spooky marks of quantum tic-tac-toe, drawn small and
+	// subscripted inside their cells.
+	Spooky map[game.Move][]game.SpookyMark
+
+	fillImg *ebiten.Image // 1x1 white image scaled and tinted to fill cells
+
+	lastGridW    int         // Cached grid image width
+	lastGridH    int         // Cached grid image height
+	lastGridVoid []game.Move // Void cells cut out of the cached grid image
 }
 
 // NewBoardView creates a new BoardView widget.
@@ -130,47 +183,109 @@ func (v *BoardView) createGridImage(width, height int) *ebiten.Image {
 		img.DrawImage(hori, oph)
 	}
 
+	// Cut void cells out of the board, letting the screen show through.
+	for _, cell := range v.cellsMasked(game.CellVoid) {
+		r := image.Rect(
+			int(float64(cell.X)*cellWidth), int(float64(cell.Y)*cellHeight),
+			int(float64(cell.X+1)*cellWidth), int(float64(cell.Y+1)*cellHeight),
+		)
+		if sub, ok := img.SubImage(r).(*ebiten.Image); ok {
+			sub.Clear()
+		}
+	}
+
 	return img
 }
 
-// ensureGridImage makes sure the cached grid image exists and matches the given size.
+// cellsMasked returns the cells of the board whose mask is m.
+func (v *BoardView) cellsMasked(m game.CellMask) []game.Move {
+	var cells []game.Move
+	for x := range v.logicBoard.Mask {
+		for y := range v.logicBoard.Mask[x] {
+			if v.logicBoard.Mask[x][y] == m {
+				cells = append(cells, game.Move{X: x, Y: y})
+			}
+		}
+	}
+	return cells
+}
+
+// ensureGridImage makes sure the cached grid image exists and matches the
+// given size and the void cells of the board.
 func (v *BoardView) ensureGridImage(width, height float64) {
 	w := int(width)
 	h := int(height)
+	void := v.cellsMasked(game.CellVoid)
 
-	if v.image == nil || v.lastGridW != w || v.lastGridH != h {
+	if v.image == nil || v.lastGridW != w || v.lastGridH != h || !slices.Equal(v.lastGridVoid, void) {
 		v.image = v.createGridImage(w, h)
 		v.lastGridW = w
 		v.lastGridH = h
+		v.lastGridVoid = void
 	}
 }
 
+// SetHighlight highlights the given cells.
+func (v *BoardView) SetHighlight(cells []game.Move) {
+	v.Highlight = cells
+}
+
+// SetFading draws the symbols of the given cells faded.
+func (v *BoardView) SetFading(cells []game.Move) {
+	v.Fading = cells
+}
+
+// SetSelection marks the given cells as selected.
+func (v *BoardView) SetSelection(cells []game.Move) {
+	v.Selection = cells
+}
+
 // Update handles mouse click detection and cell coordinate translation.
+// A left click triggers OnCellClick, a right click OnCellAltClick.
 func (v *BoardView) Update() {
 	rect := v.LayoutRect()
 	v.ensureGridImage(rect.Width, rect.Height)
 
-	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
-		mx, my := ebiten.CursorPosition()
-		vx, vy := rect.X, rect.Y
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) && v.OnCellClick != nil {
+		if gridX, gridY, ok := v.cellAtCursor(); ok {
+			v.OnCellClick(gridX, gridY)
+		}
+	}
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight) && v.OnCellAltClick != nil {
+		if gridX, gridY, ok := v.cellAtCursor(); ok {
+			v.OnCellAltClick(gridX, gridY)
+		}
+	}
+}
 
-		// Check if click is inside the board boundaries.
-		if float64(mx) >= vx && float64(mx) <= vx+rect.Width &&
-			float64(my) >= vy && float64(my) <= vy+rect.Height {
+// cellAtCursor returns the board cell under the mouse cursor. The boolean is
+// false if the cursor is outside the board or, with gravity, over a full column.
+func (v *BoardView) cellAtCursor() (int, int, bool) {
+	rect := v.LayoutRect()
+	mx, my := ebiten.CursorPosition()
+	vx, vy := rect.X, rect.Y
+
+	// Check if the cursor is inside the board boundaries.
+	if float64(mx) < vx || float64(mx) > vx+rect.Width ||
+		float64(my) < vy || float64(my) > vy+rect.Height {
+		return 0, 0, false
+	}
 
-			cellWidth := rect.Width / float64(v.logicBoard.Width)
-			cellHeight := rect.Height / float64(v.logicBoard.Height)
+	cellWidth := rect.Width / float64(v.logicBoard.Width)
+	cellHeight := rect.Height / float64(v.logicBoard.Height)
 
-			// Convert pixel coordinates -> board grid coordinates.
-			gridX := int((float64(mx) - vx) / cellWidth)
-			gridY := int((float64(my) - vy) / cellHeight)
+	// Convert pixel coordinates -> board grid coordinates.
+	gridX := min(int((float64(mx)-vx)/cellWidth), v.logicBoard.Width-1)
+	gridY := min(int((float64(my)-vy)/cellHeight), v.logicBoard.Height-1)
 
-			// Trigger callback.
-			if v.OnCellClick != nil {
-				v.OnCellClick(gridX, gridY)
-			}
+	// With gravity a click selects a column: the mark lands where it stops falling.
+	if v.logicBoard.Gravity {
+		gridY = v.logicBoard.DropRow(gridX)
+		if gridY < 0 {
+			return 0, 0, false
 		}
 	}
+	return gridX, gridY, true
 }
 
 // Draw renders the grid and the player symbols for each occupied cell.
@@ -192,51 +307,125 @@ func (v *BoardView) Draw(screen *ebiten.Image) {
 	cellWidth := rect.Width / float64(v.logicBoard.Width)
 	cellHeight := rect.Height / float64(v.logicBoard.Height)
 
-	// Use the smaller dimension for symbol sizing to maintain aspect ratio.
-	cellSize := cellWidth
-	if cellHeight < cellSize {
-		cellSize = cellHeight
-	}
-
-	padding := cellSize * cellPaddingRatio
-	usableSize := cellSize - two*padding
+	v.drawCells(screen, v.cellsMasked(game.CellBlocked), blockedColor, vx, vy, cellWidth, cellHeight)
+	v.drawCells(screen, v.Highlight, highlightColor, vx, vy, cellWidth, cellHeight)
+	v.drawCells(screen, v.Selection, selectionColor, vx, vy, cellWidth, cellHeight)
 
 	// Draw all symbols.
+	fade := &ebiten.ColorScale{}
+	fade.ScaleAlpha(fadingSymbolAlpha)
 	for x := 0; x < v.logicBoard.Width; x++ {
 		for y := 0; y < v.logicBoard.Height; y++ {
 			p := v.logicBoard.Cells[x][y]
-			if p == nil || p.Symbol.Image == nil {
+			if p == nil {
 				continue
 			}
+			var extra *ebiten.ColorScale
+			if slices.Contains(v.Fading, game.Move{X: x, Y: y}) {
+				extra = fade
+			}
+			sym := p.Symbol
+			if v.SharedSymbol != nil {
+				sym = v.SharedSymbol
+			}
+			drawSymbol(screen, sym, p.Color, vx+float64(x)*cellWidth, vy+float64(y)*cellHeight, cellWidth, cellHeight, extra)
+		}
+	}
 
-			symbolImg := p.Symbol.Image
-			srcWInt, srcHInt := symbolImg.Bounds().Dx(), symbolImg.Bounds().Dy()
+	for cell, marks := range v.Spooky {
+		v.drawSpookyMarks(screen, marks, vx+float64(cell.X)*cellWidth, vy+float64(cell.Y)*cellHeight, cellWidth, cellHeight)
+	}
+}
 
-			// Determine scaling factor based on the largest symbol dimension.
-			maxDim := float64(srcWInt)
-			if srcHInt > srcWInt {
-				maxDim = float64(srcHInt)
-			}
-			scale := usableSize / maxDim
+// drawSpookyMarks draws the spooky marks of a cell as small symbols laid
+// out on a spookyGridSize grid, each followed by its subscript.
+func (v *BoardView) drawSpookyMarks(screen *ebiten.Image, marks []game.SpookyMark, x, y, width, height float64) {
+	markWidth := width / spookyGridSize
+	markHeight := height / spookyGridSize
+	for i, mark := range marks {
+		if i >= spookyGridSize*spookyGridSize {
+			break
+		}
+		mx := x + float64(i%spookyGridSize)*markWidth
+		my := y + float64(i/spookyGridSize)*markHeight
+		drawSymbol(screen, mark.Player.Symbol, mark.Player.Color, mx, my, markWidth, markHeight, nil)
+
+		opts := &text.DrawOptions{}
+		opts.PrimaryAlign = text.AlignEnd
+		opts.SecondaryAlign = text.AlignEnd
+		opts.GeoM.Translate(mx+markWidth, my+markHeight)
+		opts.ColorScale.ScaleWithColor(mark.Player.Color)
+		text.Draw(screen, strconv.Itoa(mark.N), assets.SmallFont, opts)
+	}
+}
 
-			opSym := &ebiten.DrawImageOptions{}
-			opSym.Filter = ebiten.FilterLinear // Smooth scaling.
+// drawSymbol draws sym centered in the given area, tinted with color c
+// (the color of the player who placed it) and scaled to fit with padding.
+// An optional extra color scale (e.g. for fading) is applied on top.
+func drawSymbol(screen *ebiten.Image, sym *assets.Symbol, c color.Color, x, y, width, height float64, extra *ebiten.ColorScale) {
+	if sym == nil || sym.Image == nil {
+		return
+	}
 
-			// Scale first.
-			opSym.GeoM.Scale(scale, scale)
+	// Use the smaller dimension for symbol sizing to maintain aspect ratio.
+	cellSize := width
+	if height < cellSize {
+		cellSize = height
+	}
 
-			// Position inside the cell, centered.
-			symbolW := float64(srcWInt) * scale
-			symbolH := float64(srcHInt) * scale
-			drawX := vx + float64(x)*cellWidth + (cellWidth-symbolW)*halfcenter
-			drawY := vy + float64(y)*cellHeight + (cellHeight-symbolH)*halfcenter
+	padding := cellSize * cellPaddingRatio
+	usableSize := cellSize - two*padding
 
-			opSym.GeoM.Translate(drawX, drawY)
+	symbolImg := sym.Image
+	srcWInt, srcHInt := symbolImg.Bounds().Dx(), symbolImg.Bounds().Dy()
 
-			// Tint symbol with the player's color.
-			opSym.ColorScale.ScaleWithColor(p.Color)
+	// Determine scaling factor based on the largest symbol dimension.
+	maxDim := float64(srcWInt)
+	if srcHInt > srcWInt {
+		maxDim = float64(srcHInt)
+	}
+	scale := usableSize / maxDim
 
-			screen.DrawImage(symbolImg, opSym)
-		}
+	opSym := &ebiten.DrawImageOptions{}
+	opSym.Filter = ebiten.FilterLinear // Smooth scaling.
+
+	// Scale first.
+	opSym.GeoM.Scale(scale, scale)
+
+	// Position inside the area, centered.
+	symbolW := float64(srcWInt) * scale
+	symbolH := float64(srcHInt) * scale
+	opSym.GeoM.Translate(x+(width-symbolW)*halfcenter, y+(height-symbolH)*halfcenter)
+
+	// Tint symbol with the player's color.
+	opSym.ColorScale.ScaleWithColor(c)
+	if extra != nil {
+		opSym.ColorScale.ScaleWithColorScale(*extra)
+	}
+
+	screen.DrawImage(symbolImg, opSym)
+}
+
+// drawCells fills the given cells with a translucent color.
+func (v *BoardView) drawCells(
+	screen *ebiten.Image,
+	cells []game.Move,
+	c color.Color,
+	vx, vy, cellWidth, cellHeight float64,
+) {
+	if len(cells) == 0 {
+		return
+	}
+	if v.fillImg == nil {
+		v.fillImg = ebiten.NewImage(1, 1)
+		v.fillImg.Fill(color.White)
+	}
+
+	for _, cell := range cells {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Scale(cellWidth, cellHeight)
+		op.GeoM.Translate(vx+float64(cell.X)*cellWidth, vy+float64(cell.Y)*cellHeight)
+		op.ColorScale.ScaleWithColor(c)
+		screen.DrawImage(v.fillImg, op)
 	}
 }
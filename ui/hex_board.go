@@ -0,0 +1,249 @@
+// Package ui contains reusable UI widgets and views rendered with Ebiten.
+//
+// File: hex_board.go
+//
+// Project: GoTicTacToe
+// Authors:
+//   - Alexandre Schmid <alexandre.schmid@edu.heia-fr.ch>
+//   - Jeremy Prin <jeremy.prin@edu.heia-fr.ch>
+//
+// Date: 16 October 2026
+//
+// Copyright:
+//
+//	Copyright (c) 2026 HEIA-FR / ISC
+//	Haute école d'ingénierie et d'architecture de Fribourg
+//	Informatique et Systèmes de Communication
+//
+// License:
+//
+//	SPDX-License-Identifier: MIT OR Apache-2.0
+//
+// Description:
+//
+//	This file implements HexBoardView, the widget rendering a hexagon-shaped
+//	hex grid with pointy-top cells, and hit-testing the cursor against the
+//	hexagons to translate clicks into grid coordinates.
+package ui
+
+import (
+	"GoTicTacToe/game"
+	"GoTicTacToe/ui/utils"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/fogleman/gg"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// HexBoardView layout constants.
+const (
+	// sqrt3 is the width of a pointy-top hexagon relative to its size
+	// (the distance from its center to a corner).
+	sqrt3 = 1.7320508075688772
+
+	// hexRowStep is the vertical distance between two rows of hexagons,
+	// relative to their size.
+	hexRowStep = 1.5
+
+	// hexGapRatio is the part of the hexagon size left empty around each
+	// cell, drawing the grid lines.
+	hexGapRatio = 0.06
+
+	// hexCorners is the number of corners of a hexagon.
+	hexCorners = 6
+)
+
+// HexBoardView is the visual component rendering a game.HexBoard and
+// handling user interaction. Cells are reported in flat board coordinates,
+// like BoardView does.
+type HexBoardView struct {
+	Widget // Embeds Widget: inherits size, position, anchor, LayoutRect(), etc.
+
+	board       *game.HexBoard   // Reference to the logical hex board
+	OnCellClick func(cx, cy int) // Callback triggered when a cell is clicked (flat board coordinates)
+	Highlight   []game.Move      // Cells drawn highlighted (e.g. the winning line)
+	Fading      []game.Move      // Cells whose symbol is drawn faded (e.g. the mark about to vanish)
+	Selection   []game.Move      // Cells drawn selected
+
+	hexImg     *ebiten.Image // White hexagon scaled and tinted to fill cells
+	lastHexImg int           // Size of the cached hexagon image
+}
+
+// NewHexBoardView creates a new HexBoardView widget.
+//
+// Parameters:
+// - board: logical hex board reference (game state)
+// - x, y: offset (relative to the widget anchor)
+// - size: widget width and height (the hexagon is fitted inside)
+// - style: visual styling (cell background)
+// - onClick: callback invoked when a cell is clicked (flat board coordinates)
+func NewHexBoardView(
+	board *game.HexBoard,
+	x, y, size float64,
+	style utils.WidgetStyle,
+	onClick func(cx, cy int),
+) *HexBoardView {
+	return &HexBoardView{
+		Widget: Widget{
+			OffsetX: x,
+			OffsetY: y,
+			Width:   size,
+			Height:  size,
+			Anchor:  utils.AnchorCenter,
+			Style:   style,
+		},
+		board:       board,
+		OnCellClick: onClick,
+	}
+}
+
+// SetHighlight highlights the given cells, in flat board coordinates.
+func (v *HexBoardView) SetHighlight(cells []game.Move) {
+	v.Highlight = cells
+}
+
+// SetFading draws the symbols of the given cells faded, in flat board coordinates.
+func (v *HexBoardView) SetFading(cells []game.Move) {
+	v.Fading = cells
+}
+
+// SetSelection marks the given cells as selected, in flat board coordinates.
+func (v *HexBoardView) SetSelection(cells []game.Move) {
+	v.Selection = cells
+}
+
+// Update handles mouse click detection against the hexagons.
+func (v *HexBoardView) Update() {
+	if !inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) || v.OnCellClick == nil {
+		return
+	}
+	if c, ok := v.cellAtCursor(); ok {
+		v.OnCellClick(v.board.Flatten(c))
+	}
+}
+
+// cellAtCursor returns the hex cell under the mouse cursor. The boolean is
+// false if the cursor is outside the hexagon.
+func (v *HexBoardView) cellAtCursor() (game.HexCell, bool) {
+	rect := v.LayoutRect()
+	size := v.hexSize(rect)
+	if size <= 0 {
+		return game.HexCell{}, false
+	}
+
+	mx, my := ebiten.CursorPosition()
+	px := float64(mx) - (rect.X + rect.Width*halfcenter)
+	py := float64(my) - (rect.Y + rect.Height*halfcenter)
+
+	// Inverse of cellCenter, giving fractional axial coordinates.
+	q := (px/sqrt3 - py/3) / size
+	r := py / hexRowStep / size
+
+	c := roundHex(q, r)
+	return c, v.board.InBounds(c)
+}
+
+// roundHex returns the cell containing the fractional axial position
+// (q, r). Rounding is done in cube coordinates (q + r + s = 0), fixing the
+// coordinate that moved the most.
+func roundHex(q, r float64) game.HexCell {
+	s := -q - r
+	rq, rr, rs := math.Round(q), math.Round(r), math.Round(s)
+	dq, dr, ds := math.Abs(rq-q), math.Abs(rr-r), math.Abs(rs-s)
+
+	switch {
+	case dq > dr && dq > ds:
+		rq = -rr - rs
+	case dr > ds:
+		rr = -rq - rs
+	}
+	return game.HexCell{Q: int(rq), R: int(rr)}
+}
+
+// hexSize returns the size (center to corner distance) of the hexagons
+// for the whole board to fit in rect.
+func (v *HexBoardView) hexSize(rect utils.LayoutRect) float64 {
+	n := float64(2*v.board.Radius + 1)
+	return min(rect.Width/(sqrt3*n), rect.Height/(hexRowStep*(n-1)+2))
+}
+
+// cellCenter returns the screen position of the center of cell c, the
+// center cell being at the center of rect.
+func (v *HexBoardView) cellCenter(rect utils.LayoutRect, size float64, c game.HexCell) (float64, float64) {
+	x := rect.X + rect.Width*halfcenter + size*sqrt3*(float64(c.Q)+float64(c.R)*halfcenter)
+	y := rect.Y + rect.Height*halfcenter + size*hexRowStep*float64(c.R)
+	return x, y
+}
+
+// ensureHexImage makes sure the cached hexagon image matches the given size.
+func (v *HexBoardView) ensureHexImage(size float64) {
+	s := int(math.Ceil(size))
+	if v.hexImg != nil && v.lastHexImg == s {
+		return
+	}
+
+	w, h := int(math.Ceil(sqrt3*size)), 2*s
+	dc := gg.NewContext(w, h)
+	corner := size * (1 - hexGapRatio)
+	for i := 0; i < hexCorners; i++ {
+		// Pointy-top: the first corner is straight above the center
+		a := math.Pi/3*float64(i) - math.Pi/2
+		dc.LineTo(float64(w)*halfcenter+corner*math.Cos(a), float64(h)*halfcenter+corner*math.Sin(a))
+	}
+	dc.ClosePath()
+	dc.SetRGB(1, 1, 1)
+	dc.Fill()
+
+	v.hexImg = ebiten.NewImageFromImage(dc.Image())
+	v.lastHexImg = s
+}
+
+// Draw renders the cells, their highlight and the symbols.
+func (v *HexBoardView) Draw(screen *ebiten.Image) {
+	rect := v.LayoutRect()
+	size := v.hexSize(rect)
+	if size <= 0 {
+		return
+	}
+	v.ensureHexImage(size)
+
+	fade := &ebiten.ColorScale{}
+	fade.ScaleAlpha(fadingSymbolAlpha)
+	width := sqrt3 * size
+
+	for _, c := range v.board.Cells() {
+		cx, cy := v.cellCenter(rect, size, c)
+		x, y := v.board.Flatten(c)
+		cell := game.Move{X: x, Y: y}
+
+		v.drawHex(screen, cx, cy, v.Style.BackgroundNormal)
+		if slices.Contains(v.Highlight, cell) {
+			v.drawHex(screen, cx, cy, highlightColor)
+		}
+		if slices.Contains(v.Selection, cell) {
+			v.drawHex(screen, cx, cy, selectionColor)
+		}
+
+		p := v.board.At(c)
+		if p == nil {
+			continue
+		}
+		var extra *ebiten.ColorScale
+		if slices.Contains(v.Fading, cell) {
+			extra = fade
+		}
+		drawSymbol(screen, p.Symbol, p.Color, cx-width*halfcenter, cy-width*halfcenter, width, width, extra)
+	}
+}
+
+// drawHex fills the hexagon centered on (cx, cy) with color c.
+func (v *HexBoardView) drawHex(screen *ebiten.Image, cx, cy float64, c color.Color) {
+	bounds := v.hexImg.Bounds()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(cx-float64(bounds.Dx())*halfcenter, cy-float64(bounds.Dy())*halfcenter)
+	op.ColorScale.ScaleWithColor(c)
+	screen.DrawImage(v.hexImg, op)
+}
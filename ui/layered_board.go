@@ -0,0 +1,188 @@
+// Package ui contains reusable UI widgets and views rendered with Ebiten.
+//
+// File: layered_board.go
+//
+// Project: GoTicTacToe
+// Authors:
+//   - Alexandre Schmid <alexandre.schmid@edu.heia-fr.ch>
+//   - Jeremy Prin <jeremy.prin@edu.heia-fr.ch>
+//
+// Date: 16 October 2026
+//
+// Copyright:
+//
+//	Copyright (c) 2026 HEIA-FR / ISC
+//	Haute école d'ingénierie et d'architecture de Fribourg
+//	Informatique et Systèmes de Communication
+//
+// License:
+//
+//	SPDX-License-Identifier: MIT OR Apache-2.0
+//
+// Description:
+//
+//	This file implements LayeredBoardView, the widget rendering a 3D board
+//	(or several independent boards) as its 2D layers side by side, routing
+//	clicks to (x, y, z) cells.
+package ui
+
+import (
+	"GoTicTacToe/assets"
+	"GoTicTacToe/game"
+	"GoTicTacToe/ui/utils"
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// LayeredBoardView layout constants.
+const (
+	// layerGapRatio is the gap between two layers as a fraction of the layer size.
+	layerGapRatio = 0.12
+
+	// layerLabelOffsetY is the distance between a layer and its label, in pixels.
+	layerLabelOffsetY = 22.0
+
+	// defaultLayerLabelFormat is the default format of the layer labels.
+	defaultLayerLabelFormat = "Layer %d"
+)
+
+// layerLabelColor is the color of the layer labels.
+var layerLabelColor = color.RGBA{R: 200, G: 220, B: 255, A: 255}
+
+// LayeredBoardView renders a 3D board as its layers laid out side by side,
+// each layer being drawn by its own BoardView.
+type LayeredBoardView struct {
+	Widget // Embeds Widget: inherits size, position, anchor, LayoutRect(), etc.
+
+	board       *game.Board3D        // Reference to the logical 3D board
+	layers      []*BoardView         // One view per layer
+	OnCellClick func(cx, cy, cz int) // Callback triggered when a cell is clicked
+	LabelFormat string               // Format of the layer labels, given the 1-based layer number
+}
+
+// NewLayeredBoardView creates a new LayeredBoardView widget.
+//
+// Parameters:
+// - board: logical 3D board reference (game state)
+// - x, y: offset (relative to the widget anchor)
+// - layerSize: width and height of a single layer (square rendering)
+// - style: visual styling of the layers
+// - onClick: callback invoked when a cell is clicked (3D coordinates)
+func NewLayeredBoardView(
+	board *game.Board3D,
+	x, y, layerSize float64,
+	style utils.WidgetStyle,
+	onClick func(cx, cy, cz int),
+) *LayeredBoardView {
+	gap := layerSize * layerGapRatio
+	view := &LayeredBoardView{
+		Widget: Widget{
+			OffsetX: x,
+			OffsetY: y,
+			Width:   float64(board.Depth)*layerSize + float64(board.Depth-1)*gap,
+			Height:  layerSize,
+			Anchor:  utils.AnchorCenter,
+			Style:   style,
+		},
+		board:       board,
+		OnCellClick: onClick,
+		LabelFormat: defaultLayerLabelFormat,
+	}
+
+	for z := 0; z < board.Depth; z++ {
+		layer := NewBoardView(board.Layer(z), 0, 0, layerSize, style, func(cx, cy int) {
+			if view.OnCellClick != nil {
+				view.OnCellClick(cx, cy, z)
+			}
+		})
+		view.layers = append(view.layers, layer)
+	}
+	return view
+}
+
+// SetHighlight highlights the given cells, in flat board coordinates.
+func (v *LayeredBoardView) SetHighlight(cells []game.Move) {
+	for z, layerCells := range v.splitLayers(cells) {
+		v.layers[z].Highlight = layerCells
+	}
+}
+
+// SetFading draws the symbols of the given cells faded, in flat board coordinates.
+func (v *LayeredBoardView) SetFading(cells []game.Move) {
+	for z, layerCells := range v.splitLayers(cells) {
+		v.layers[z].Fading = layerCells
+	}
+}
+
+// SetSelection marks the given cells as selected, in flat board coordinates.
+func (v *LayeredBoardView) SetSelection(cells []game.Move) {
+	for z, layerCells := range v.splitLayers(cells) {
+		v.layers[z].Selection = layerCells
+	}
+}
+
+// SetSharedSymbol draws sym for every mark of every layer, whoever placed
+// it (nil = each player's own symbol).
+func (v *LayeredBoardView) SetSharedSymbol(sym *assets.Symbol) {
+	for _, layer := range v.layers {
+		layer.SharedSymbol = sym
+	}
+}
+
+// splitLayers converts flat board cells to layer coordinates, grouped by layer.
+func (v *LayeredBoardView) splitLayers(cells []game.Move) [][]game.Move {
+	split := make([][]game.Move, len(v.layers))
+	for _, cell := range cells {
+		c := v.board.Unflatten(cell.X, cell.Y)
+		if c.Z >= 0 && c.Z < len(split) {
+			split[c.Z] = append(split[c.Z], game.Move{X: c.X, Y: c.Y})
+		}
+	}
+	return split
+}
+
+// Update lays out the layers and forwards input to them.
+func (v *LayeredBoardView) Update() {
+	v.layoutLayers()
+	for _, layer := range v.layers {
+		layer.Update()
+	}
+}
+
+// Draw renders every layer with its label.
+func (v *LayeredBoardView) Draw(screen *ebiten.Image) {
+	v.layoutLayers()
+	for z, layer := range v.layers {
+		layer.Draw(screen)
+
+		rect := layer.LayoutRect()
+		opts := &text.DrawOptions{}
+		opts.PrimaryAlign = text.AlignCenter
+		opts.SecondaryAlign = text.AlignCenter
+		opts.ColorScale.ScaleWithColor(layerLabelColor)
+		opts.GeoM.Translate(rect.X+rect.Width*halfcenter, rect.Y+rect.Height+layerLabelOffsetY)
+		text.Draw(screen, fmt.Sprintf(v.LabelFormat, z+1), assets.NormalFont, opts)
+	}
+}
+
+// layoutLayers places each layer view in its slot of the widget.
+func (v *LayeredBoardView) layoutLayers() {
+	rect := v.LayoutRect()
+	if len(v.layers) == 0 {
+		return
+	}
+
+	layerSize := rect.Height
+	gap := layerSize * layerGapRatio
+	for z, layer := range v.layers {
+		layer.SetParentBounds(utils.LayoutRect{
+			X:      rect.X + float64(z)*(layerSize+gap),
+			Y:      rect.Y,
+			Width:  layerSize,
+			Height: layerSize,
+		})
+	}
+}
@@ -23,7 +23,11 @@
 //
 //	This file implements ScoreView, a widget displaying the current scores and
 //	symbols for any number of players. Non-active players can be visually dimmed
-//	while the game is running.
+//	while the game is running. Captures are shown when the rules have them,
+//	lines with line scoring, the team of each player (teammates sharing their
+//	points), the time left on their clock in timed matches, and the active
+//	player's placements left during multi-placement turns. The least important
+//	of these are dropped when they do not fit in the player's zone.
 package ui
 
 import (
@@ -31,6 +35,8 @@ import (
 	"GoTicTacToe/game"
 	"GoTicTacToe/ui/utils"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
@@ -54,12 +60,22 @@ const (
 
 	// Visual effect when it's not the player's turn.
 	nonActiveAlphaScale = 0.5
+
+	// statusSeparator separates the parts of a player's round status.
+	statusSeparator = "  "
+
+	// statusGapPx is the minimal space between the score and the status.
+	statusGapPx = 8.0
+
+	// clockTenthsBelow is the time left under which clocks show tenths of a second.
+	clockTenthsBelow = 10 * time.Second
 )
 
 // ScoreView displays player icons and scores for any number of players.
 type ScoreView struct {
 	Widget
 	gameRef *game.Game
+	Clock   *game.ChessClock // Players' clocks, shown in their status (nil = untimed)
 }
 
 // NewScoreView creates a score panel widget.
@@ -162,7 +178,7 @@ func (sv *ScoreView) drawPlayerZone(
 	}
 
 	// Draw score text.
-	msg := fmt.Sprintf("%d", p.Points)
+	msg := sv.pointsLabel(p)
 
 	opts := &text.DrawOptions{}
 	opts.PrimaryAlign = text.AlignCenter
@@ -175,4 +191,84 @@ func (sv *ScoreView) drawPlayerZone(
 	opts.GeoM.Translate(textX, textY)
 
 	text.Draw(screen, msg, assets.NormalFont, opts)
+
+	// Round status right-aligned inside zone, in the space left by the score.
+	scoreWidth := text.Advance(msg, assets.NormalFont)
+	available := zoneWidth - two*padding - scoreWidth*half - statusGapPx
+	if status, face := fitStatus(sv.playerStatus(p), available); status != "" {
+		statusOpts := &text.DrawOptions{}
+		statusOpts.PrimaryAlign = text.AlignEnd
+		statusOpts.SecondaryAlign = text.AlignCenter
+		statusOpts.ColorScale.ScaleWithColor(sv.Style.TextColor)
+		statusOpts.GeoM.Translate(x+zoneWidth-padding, textY)
+		text.Draw(screen, status, face, statusOpts)
+	}
+}
+
+// fitStatus returns the longest leading parts of a status fitting in width,
+// and the font to draw them with. The small font is only used when not even
+// the first part fits in the normal one.
+func fitStatus(parts []string, width float64) (string, text.Face) {
+	for _, face := range []text.Face{assets.NormalFont, assets.SmallFont} {
+		for n := len(parts); n > 0; n-- {
+			if status := strings.Join(parts[:n], statusSeparator); text.Advance(status, face) <= width {
+				return status, face
+			}
+		}
+	}
+	return "", nil
+}
+
+// pointsLabel returns the score of p, shared with their teammates. Quantum
+// tic-tac-toe counts points in half points, shown with a "½".
+func (sv *ScoreView) pointsLabel(p *game.Player) string {
+	points := sv.gameRef.TeamPoints(p)
+	if _, ok := sv.gameRef.Rules.(game.QuantumRules); !ok {
+		return fmt.Sprintf("%d", points)
+	}
+
+	whole, half := points/game.QuantumWinPoints, points%game.QuantumWinPoints != 0
+	switch {
+	case !half:
+		return fmt.Sprintf("%d", whole)
+	case whole == 0:
+		return "½"
+	default:
+		return fmt.Sprintf("%d½", whole)
+	}
+}
+
+// playerStatus returns the parts of the round information shown next to the
+// score of p, most important first: the time left on their clock, their
+// placements left during a multi-placement turn, their captures when the
+// rules have captures, their lines with line scoring, and their team.
+func (sv *ScoreView) playerStatus(p *game.Player) []string {
+	g := sv.gameRef
+
+	var parts []string
+	if sv.Clock != nil {
+		parts = append(parts, formatClock(sv.Clock.Remaining(p)))
+	}
+	if g.Current == p && g.IsPlaying() && g.TurnPlacements() > 1 {
+		parts = append(parts, fmt.Sprintf("%d left", g.PlacementsLeft()))
+	}
+	if rules, ok := g.Rules.(game.CaptureRules); ok {
+		parts = append(parts, fmt.Sprintf("Cap %d/%d", p.Captures, rules.CapturesToWin()))
+	}
+	if g.LineScoring {
+		parts = append(parts, fmt.Sprintf("Lines %d", p.Lines))
+	}
+	if p.Team > 0 {
+		parts = append(parts, fmt.Sprintf("Team %d", p.Team))
+	}
+	return parts
+}
+
+// formatClock returns the time left on a clock in minutes and seconds, or
+// in seconds and tenths in the last seconds.
+func formatClock(d time.Duration) string {
+	if d < clockTenthsBelow {
+		return fmt.Sprintf("%.1f", d.Truncate(time.Second/10).Seconds())
+	}
+	return fmt.Sprintf("%d:%02d", int(d/time.Minute), int(d%time.Minute/time.Second))
 }
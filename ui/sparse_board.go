@@ -0,0 +1,282 @@
+// Package ui contains reusable UI widgets and views rendered with Ebiten.
+//
+// File: sparse_board.go
+//
+// Project: GoTicTacToe
+// Authors:
+//   - Alexandre Schmid <alexandre.schmid@edu.heia-fr.ch>
+//   - Jeremy Prin <jeremy.prin@edu.heia-fr.ch>
+//
+// Date: 16 October 2026
+//
+// Copyright:
+//
+//	Copyright (c) 2026 HEIA-FR / ISC
+//	Haute école d'ingénierie et d'architecture de Fribourg
+//	Informatique et Systèmes de Communication
+//
+// License:
+//
+//	SPDX-License-Identifier: MIT OR Apache-2.0
+//
+// Description:
+//
+//	This file implements SparseBoardView, the widget rendering an unbounded
+//	board through a camera that the player scrolls and zooms, and
+//	translating clicks into signed grid coordinates.
+package ui
+
+import (
+	"GoTicTacToe/game"
+	"GoTicTacToe/ui/utils"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// SparseBoardView camera constants.
+const (
+	// defaultSparseCellSize is the initial size of a cell, in pixels.
+	defaultSparseCellSize = 40.0
+
+	// minSparseCellSize and maxSparseCellSize bound the zoom, in pixels per cell.
+	minSparseCellSize = 12.0
+	maxSparseCellSize = 120.0
+
+	// sparseZoomStep is the zoom factor applied per mouse wheel notch.
+	sparseZoomStep = 1.15
+
+	// sparsePanCells is the number of cells scrolled by an arrow key press.
+	sparsePanCells = 3
+)
+
+// SparseBoardView is the visual component rendering a game.SparseBoard
+// and handling user interaction.
+//
+// The board has no edges, so it is seen through a camera: the mouse wheel
+// zooms around the cursor, dragging with the right button or the arrow
+// keys scroll, and C centers the camera on the marks. A left click plays
+// the cell under the cursor.
+type SparseBoardView struct {
+	Widget // Embeds Widget: inherits size, position, anchor, LayoutRect(), etc.
+
+	board       func() *game.SparseBoard // Source of the logical board, read every frame
+	OnCellClick func(cx, cy int)         // Callback triggered when a cell is clicked (signed board coordinates)
+	Highlight   []game.Move              // Cells drawn highlighted (e.g. the winning line)
+	Fading      []game.Move              // Cells whose symbol is drawn faded
+	Selection   []game.Move              // Cells drawn selected
+
+	CenterX  float64 // Board column shown at the center of the widget
+	CenterY  float64 // Board row shown at the center of the widget
+	CellSize float64 // Zoom: size of a cell, in pixels
+
+	dragging     bool // True while the right button drags the camera
+	dragX, dragY int  // Cursor position at the previous frame of the drag
+
+	fillImg *ebiten.Image // 1x1 white image scaled and tinted to fill cells and draw lines
+}
+
+// NewSparseBoardView creates a new SparseBoardView widget, its camera
+// centered on the origin cell.
+//
+// Parameters:
+// - board: returns the logical board to render (game state)
+// - x, y: offset (relative to the widget anchor)
+// - size: widget width and height
+// - style: visual styling (background, grid lines)
+// - onClick: callback invoked when a cell is clicked (signed board coordinates)
+func NewSparseBoardView(
+	board func() *game.SparseBoard,
+	x, y, size float64,
+	style utils.WidgetStyle,
+	onClick func(cx, cy int),
+) *SparseBoardView {
+	return &SparseBoardView{
+		Widget: Widget{
+			OffsetX: x,
+			OffsetY: y,
+			Width:   size,
+			Height:  size,
+			Anchor:  utils.AnchorCenter,
+			Style:   style,
+		},
+		board:       board,
+		OnCellClick: onClick,
+		CellSize:    defaultSparseCellSize,
+	}
+}
+
+// SetHighlight highlights the given cells.
+func (v *SparseBoardView) SetHighlight(cells []game.Move) {
+	v.Highlight = cells
+}
+
+// SetFading draws the symbols of the given cells faded.
+func (v *SparseBoardView) SetFading(cells []game.Move) {
+	v.Fading = cells
+}
+
+// SetSelection marks the given cells as selected.
+func (v *SparseBoardView) SetSelection(cells []game.Move) {
+	v.Selection = cells
+}
+
+// Update moves the camera and handles cell clicks.
+func (v *SparseBoardView) Update() {
+	rect := v.LayoutRect()
+	mx, my := ebiten.CursorPosition()
+	inside := float64(mx) >= rect.X && float64(mx) <= rect.X+rect.Width &&
+		float64(my) >= rect.Y && float64(my) <= rect.Y+rect.Height
+
+	// Zoom around the cursor: the board point under it stays in place
+	if _, wheel := ebiten.Wheel(); wheel != 0 && inside {
+		bx, by := v.boardPoint(rect, mx, my)
+		v.CellSize = min(max(v.CellSize*math.Pow(sparseZoomStep, wheel), minSparseCellSize), maxSparseCellSize)
+		nx, ny := v.boardPoint(rect, mx, my)
+		v.CenterX += bx - nx
+		v.CenterY += by - ny
+	}
+
+	// Scroll by dragging with the right button
+	switch {
+	case inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && inside:
+		v.dragging = true
+	case !ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight):
+		v.dragging = false
+	case v.dragging:
+		v.CenterX -= float64(mx-v.dragX) / v.CellSize
+		v.CenterY -= float64(my-v.dragY) / v.CellSize
+	}
+	v.dragX, v.dragY = mx, my
+
+	v.updateKeys()
+
+	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) && inside && v.OnCellClick != nil {
+		bx, by := v.boardPoint(rect, mx, my)
+		v.OnCellClick(int(math.Floor(bx+halfcenter)), int(math.Floor(by+halfcenter)))
+	}
+}
+
+// updateKeys scrolls the camera with the arrow keys and centers it on the
+// marks with C.
+func (v *SparseBoardView) updateKeys() {
+	steps := []struct {
+		key    ebiten.Key
+		dx, dy float64
+	}{
+		{ebiten.KeyArrowLeft, -sparsePanCells, 0},
+		{ebiten.KeyArrowRight, sparsePanCells, 0},
+		{ebiten.KeyArrowUp, 0, -sparsePanCells},
+		{ebiten.KeyArrowDown, 0, sparsePanCells},
+	}
+	for _, s := range steps {
+		if inpututil.IsKeyJustPressed(s.key) {
+			v.CenterX += s.dx
+			v.CenterY += s.dy
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		v.CenterOnMarks()
+	}
+}
+
+// CenterOnMarks moves the camera to the middle of the marks, or to the
+// origin on an empty board.
+func (v *SparseBoardView) CenterOnMarks() {
+	minX, minY, maxX, maxY, ok := v.board().Bounds()
+	if !ok {
+		v.CenterX, v.CenterY = 0, 0
+		return
+	}
+	v.CenterX = float64(minX+maxX) * halfcenter
+	v.CenterY = float64(minY+maxY) * halfcenter
+}
+
+// boardPoint returns the board position under the screen pixel (px, py),
+// cell (x, y) covering the positions from x-0.5 to x+0.5.
+func (v *SparseBoardView) boardPoint(rect utils.LayoutRect, px, py int) (float64, float64) {
+	bx := v.CenterX + (float64(px)-(rect.X+rect.Width*halfcenter))/v.CellSize
+	by := v.CenterY + (float64(py)-(rect.Y+rect.Height*halfcenter))/v.CellSize
+	return bx, by
+}
+
+// cellOrigin returns the screen position of the top left corner of cell (x, y).
+func (v *SparseBoardView) cellOrigin(rect utils.LayoutRect, x, y int) (float64, float64) {
+	sx := rect.X + rect.Width*halfcenter + (float64(x)-v.CenterX-halfcenter)*v.CellSize
+	sy := rect.Y + rect.Height*halfcenter + (float64(y)-v.CenterY-halfcenter)*v.CellSize
+	return sx, sy
+}
+
+// Draw renders the visible part of the grid and the symbols on it. Nothing
+// is drawn outside of the widget.
+func (v *SparseBoardView) Draw(screen *ebiten.Image) {
+	rect := v.LayoutRect()
+	clip := image.Rect(int(rect.X), int(rect.Y), int(rect.X+rect.Width), int(rect.Y+rect.Height))
+	view, ok := screen.SubImage(clip).(*ebiten.Image)
+	if !ok || v.CellSize <= 0 {
+		return
+	}
+	if v.fillImg == nil {
+		v.fillImg = ebiten.NewImage(1, 1)
+		v.fillImg.Fill(color.White)
+	}
+
+	v.fill(view, rect.X, rect.Y, rect.Width, rect.Height, v.Style.BackgroundNormal)
+
+	// Visible cells, including the ones cut by the edges of the widget
+	minX, minY := v.boardPoint(rect, int(rect.X), int(rect.Y))
+	maxX, maxY := v.boardPoint(rect, int(rect.X+rect.Width), int(rect.Y+rect.Height))
+	x0, y0 := int(math.Floor(minX+halfcenter)), int(math.Floor(minY+halfcenter))
+	x1, y1 := int(math.Floor(maxX+halfcenter)), int(math.Floor(maxY+halfcenter))
+
+	// Grid lines on the borders between cells
+	thickness := v.Style.BorderWidth
+	for x := x0; x <= x1+1; x++ {
+		sx, _ := v.cellOrigin(rect, x, 0)
+		v.fill(view, sx-thickness*halfcenter, rect.Y, thickness, rect.Height, v.Style.BorderColor)
+	}
+	for y := y0; y <= y1+1; y++ {
+		_, sy := v.cellOrigin(rect, 0, y)
+		v.fill(view, rect.X, sy-thickness*halfcenter, rect.Width, thickness, v.Style.BorderColor)
+	}
+
+	for _, cells := range []struct {
+		cells []game.Move
+		color color.Color
+	}{{v.Highlight, highlightColor}, {v.Selection, selectionColor}} {
+		for _, c := range cells.cells {
+			sx, sy := v.cellOrigin(rect, c.X, c.Y)
+			v.fill(view, sx, sy, v.CellSize, v.CellSize, cells.color)
+		}
+	}
+
+	fade := &ebiten.ColorScale{}
+	fade.ScaleAlpha(fadingSymbolAlpha)
+	board := v.board()
+	for _, c := range board.Stones() {
+		if c.X < x0 || c.X > x1 || c.Y < y0 || c.Y > y1 {
+			continue
+		}
+		p := board.At(c.X, c.Y)
+		var extra *ebiten.ColorScale
+		if slices.Contains(v.Fading, c) {
+			extra = fade
+		}
+		sx, sy := v.cellOrigin(rect, c.X, c.Y)
+		drawSymbol(view, p.Symbol, p.Color, sx, sy, v.CellSize, v.CellSize, extra)
+	}
+}
+
+// fill draws a rectangle of color c.
+func (v *SparseBoardView) fill(screen *ebiten.Image, x, y, width, height float64, c color.Color) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(width, height)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(c)
+	screen.DrawImage(v.fillImg, op)
+}
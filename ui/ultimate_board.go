@@ -0,0 +1,138 @@
+// Package ui contains reusable UI widgets and views rendered with Ebiten.
+//
+// File: ultimate_board.go
+//
+// Project: GoTicTacToe
+// Authors:
+//   - Alexandre Schmid <alexandre.schmid@edu.heia-fr.ch>
+//   - Jeremy Prin <jeremy.prin@edu.heia-fr.ch>
+//
+// Date: 16 October 2026
+//
+// Copyright:
+//
+//	Copyright (c) 2026 HEIA-FR / ISC
+//	Haute école d'ingénierie et d'architecture de Fribourg
+//	Informatique et Systèmes de Communication
+//
+// License:
+//
+//	SPDX-License-Identifier: MIT OR Apache-2.0
+//
+// Description:
+//
+//	This file implements UltimateBoardView, a BoardView for Ultimate
+//	Tic-Tac-Toe that separates the sub-boards, marks the sub-boards where
+//	the next move may be played and covers claimed sub-boards with the
+//	symbol of their owner.
+package ui
+
+import (
+	"GoTicTacToe/game"
+	"GoTicTacToe/ui/utils"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// UltimateBoardView layout constants.
+const (
+	// subBoardLineFactor is the thickness of sub-board separators relative
+	// to the regular grid lines.
+	subBoardLineFactor = 3.0
+
+	// claimedSymbolAlpha is the opacity of the symbol drawn over a claimed sub-board.
+	claimedSymbolAlpha = 0.85
+)
+
+// UltimateBoardView colors.
+var (
+	// activeSubBoardColor tints the sub-boards where the next move may be played.
+	activeSubBoardColor = color.RGBA{R: 80, G: 200, B: 120, A: 50}
+
+	// claimedSubBoardColor dims the cells of claimed sub-boards.
+	claimedSubBoardColor = color.RGBA{R: 0, G: 0, B: 0, A: 120}
+)
+
+// UltimateBoardView renders an Ultimate Tic-Tac-Toe board.
+//
+// It embeds a BoardView for the cells, the symbols, the highlight and the
+// click handling, and draws the sub-board structure on top of it.
+type UltimateBoardView struct {
+	*BoardView
+
+	ultimate *game.UltimateBoard
+	Active   []game.Move // Sub-boards (meta coordinates) where the next move may be played
+
+	fillImg *ebiten.Image // 1x1 white image scaled and tinted to fill areas
+}
+
+// NewUltimateBoardView creates a new UltimateBoardView widget.
+//
+// The parameters are the same as NewBoardView, with the composite view of
+// the board instead of the board itself.
+func NewUltimateBoardView(
+	ultimate *game.UltimateBoard,
+	x, y, size float64,
+	style utils.WidgetStyle,
+	onClick func(cx, cy int),
+) *UltimateBoardView {
+	fill := ebiten.NewImage(1, 1)
+	fill.Fill(color.White)
+
+	return &UltimateBoardView{
+		BoardView: NewBoardView(ultimate.Board, x, y, size, style, onClick),
+		ultimate:  ultimate,
+		fillImg:   fill,
+	}
+}
+
+// Draw renders the cells, then the sub-board overlays and separators.
+func (v *UltimateBoardView) Draw(screen *ebiten.Image) {
+	v.BoardView.Draw(screen)
+
+	rect := v.LayoutRect()
+	sub := v.ultimate.SubSize
+	subWidth := rect.Width / float64(sub)
+	subHeight := rect.Height / float64(sub)
+
+	// Active sub-boards
+	for _, active := range v.Active {
+		v.fill(screen, rect.X+float64(active.X)*subWidth, rect.Y+float64(active.Y)*subHeight,
+			subWidth, subHeight, activeSubBoardColor)
+	}
+
+	// Claimed sub-boards: dim the cells and draw the owner's symbol across the sub-board
+	fade := &ebiten.ColorScale{}
+	fade.ScaleAlpha(claimedSymbolAlpha)
+	for sx := 0; sx < sub; sx++ {
+		for sy := 0; sy < sub; sy++ {
+			owner := v.ultimate.Owner(sx, sy)
+			if owner == nil {
+				continue
+			}
+			ox := rect.X + float64(sx)*subWidth
+			oy := rect.Y + float64(sy)*subHeight
+			v.fill(screen, ox, oy, subWidth, subHeight, claimedSubBoardColor)
+			drawSymbol(screen, owner.Symbol, owner.Color, ox, oy, subWidth, subHeight, fade)
+		}
+	}
+
+	// Thick separators between sub-boards
+	thickness := v.Style.BorderWidth * subBoardLineFactor
+	for i := 1; i < sub; i++ {
+		v.fill(screen, rect.X+float64(i)*subWidth-thickness*halfcenter, rect.Y,
+			thickness, rect.Height, v.Style.BorderColor)
+		v.fill(screen, rect.X, rect.Y+float64(i)*subHeight-thickness*halfcenter,
+			rect.Width, thickness, v.Style.BorderColor)
+	}
+}
+
+// fill draws a rectangle of the given color.
+func (v *UltimateBoardView) fill(screen *ebiten.Image, x, y, width, height float64, c color.Color) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(width, height)
+	op.GeoM.Translate(x, y)
+	op.ColorScale.ScaleWithColor(c)
+	screen.DrawImage(v.fillImg, op)
+}